@@ -0,0 +1,172 @@
+package gxcommon
+
+// --------------------------------------------------------------------------
+//
+//	Gurux Ltd
+//
+// Filename:        $HeadURL$
+//
+// Version:         $Revision$,
+//
+//	$Date$
+//	$Author$
+//
+// # Copyright (c) Gurux Ltd
+//
+// ---------------------------------------------------------------------------
+//
+//	DESCRIPTION
+//
+// This file is a part of Gurux Device Framework.
+//
+// Gurux Device Framework is Open Source software; you can redistribute it
+// and/or modify it under the terms of the GNU General Public License
+// as published by the Free Software Foundation; version 2 of the License.
+// Gurux Device Framework is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.
+// See the GNU General Public License for more details.
+//
+// More information of Gurux products: https://www.gurux.org
+//
+// This code is licensed under the GNU General Public License v2.
+// Full text may be retrieved at http://www.gnu.org/licenses/gpl-2.0.txt
+// ---------------------------------------------------------------------------
+
+const (
+	// bcdPositive is the sign nibble written for zero and positive packed
+	// decimals.
+	bcdPositive = 0x0C
+	// bcdNegative is the sign nibble written for negative packed decimals.
+	bcdNegative = 0x0D
+)
+
+// AppendBCD appends value to dst as packed BCD with the given number of
+// decimal digits and returns the extended buffer.
+//
+// Each byte holds two digits, most significant digit first. The value takes
+// (digits+1)/2 bytes and an odd number of digits is padded with a leading
+// zero nibble. It returns ErrInvalidArgument if digits is not positive and
+// ErrArgumentOutOfRange if value has more than digits digits.
+func AppendBCD(dst []byte, value uint64, digits int) ([]byte, error) {
+	if digits <= 0 {
+		return nil, ErrInvalidArgumentError("digits")
+	}
+	if bcdDigits(value) > digits {
+		return nil, ErrArgumentOutOfRangeError("value")
+	}
+	size := (digits + 1) / 2
+	ret := append(dst, make([]byte, size)...)
+	putNibbles(ret[len(dst):], value, size*2)
+	return ret, nil
+}
+
+// ParseBCD decodes packed BCD.
+//
+// All nibbles of b are digits, most significant digit first. It returns
+// ErrInvalidArgument if a nibble is not a decimal digit and
+// ErrArgumentOutOfRange if the value does not fit to uint64.
+func ParseBCD(b []byte) (uint64, error) {
+	return parseNibbles(b, len(b)*2)
+}
+
+// AppendPackedDecimal appends value to dst as a signed packed decimal with
+// the given number of decimal digits and returns the extended buffer.
+//
+// The digits are followed by a sign nibble, 0xC for zero and positive values
+// and 0xD for negative values. The value takes digits/2+1 bytes and an even
+// number of digits is padded with a leading zero nibble. It returns
+// ErrInvalidArgument if digits is not positive and ErrArgumentOutOfRange if
+// value has more than digits digits.
+func AppendPackedDecimal(dst []byte, value int64, digits int) ([]byte, error) {
+	if digits <= 0 {
+		return nil, ErrInvalidArgumentError("digits")
+	}
+	sign := byte(bcdPositive)
+	magnitude := uint64(value)
+	if value < 0 {
+		sign = bcdNegative
+		magnitude = -magnitude
+	}
+	if bcdDigits(magnitude) > digits {
+		return nil, ErrArgumentOutOfRangeError("value")
+	}
+	size := digits/2 + 1
+	ret := append(dst, make([]byte, size)...)
+	putNibbles(ret[len(dst):], magnitude, size*2-1)
+	ret[len(ret)-1] |= sign
+	return ret, nil
+}
+
+// ParsePackedDecimal decodes a signed packed decimal.
+//
+// The last nibble of b is the sign: 0xB and 0xD are negative and 0xA, 0xC,
+// 0xE and 0xF are positive. It returns ErrInvalidArgument if the sign nibble
+// is a digit or another nibble is not a decimal digit and
+// ErrArgumentOutOfRange if the value does not fit to int64.
+func ParsePackedDecimal(b []byte) (int64, error) {
+	if len(b) == 0 {
+		return 0, ErrBufferTooSmallError("packed decimal")
+	}
+	sign := b[len(b)-1] & 0x0F
+	if sign < 0x0A {
+		return 0, ErrInvalidArgumentError("packed decimal sign")
+	}
+	magnitude, err := parseNibbles(b, len(b)*2-1)
+	if err != nil {
+		return 0, err
+	}
+	if sign == 0x0B || sign == bcdNegative {
+		if magnitude > 1<<63 {
+			return 0, ErrArgumentOutOfRangeError("packed decimal")
+		}
+		return -int64(magnitude), nil
+	}
+	if magnitude > 1<<63-1 {
+		return 0, ErrArgumentOutOfRangeError("packed decimal")
+	}
+	return int64(magnitude), nil
+}
+
+// bcdDigits returns the number of decimal digits in value.
+func bcdDigits(value uint64) int {
+	ret := 1
+	for value >= 10 {
+		value /= 10
+		ret++
+	}
+	return ret
+}
+
+// putNibbles writes value to the first count nibbles of b as decimal
+// digits, most significant digit first. b must be zeroed.
+func putNibbles(b []byte, value uint64, count int) {
+	for i := count - 1; i >= 0; i-- {
+		digit := byte(value % 10)
+		value /= 10
+		if i%2 == 0 {
+			b[i/2] |= digit << 4
+		} else {
+			b[i/2] |= digit
+		}
+	}
+}
+
+// parseNibbles decodes the first count nibbles of b as decimal digits.
+func parseNibbles(b []byte, count int) (uint64, error) {
+	var ret uint64
+	for i := range count {
+		digit := b[i/2] >> 4
+		if i%2 != 0 {
+			digit = b[i/2] & 0x0F
+		}
+		if digit > 9 {
+			return 0, ErrInvalidArgumentError("BCD")
+		}
+		if ret > (1<<64-1-uint64(digit))/10 {
+			return 0, ErrArgumentOutOfRangeError("BCD")
+		}
+		ret = ret*10 + uint64(digit)
+	}
+	return ret, nil
+}
@@ -0,0 +1,97 @@
+package gxcommon
+
+// --------------------------------------------------------------------------
+//
+//	Gurux Ltd
+//
+// Filename:        $HeadURL$
+//
+// Version:         $Revision$,
+//
+//	$Date$
+//	$Author$
+//
+// # Copyright (c) Gurux Ltd
+//
+// ---------------------------------------------------------------------------
+//
+//	DESCRIPTION
+//
+// This file is a part of Gurux Device Framework.
+//
+// Gurux Device Framework is Open Source software; you can redistribute it
+// and/or modify it under the terms of the GNU General Public License
+// as published by the Free Software Foundation; version 2 of the License.
+// Gurux Device Framework is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.
+// See the GNU General Public License for more details.
+//
+// More information of Gurux products: https://www.gurux.org
+//
+// This code is licensed under the GNU General Public License v2.
+// Full text may be retrieved at http://www.gnu.org/licenses/gpl-2.0.txt
+// ---------------------------------------------------------------------------
+
+import (
+	"fmt"
+	"strings"
+)
+
+// BitOrder defines the order of bits in BitReader and BitWriter.
+//
+// The zero value is BitOrderMSBFirst.
+type BitOrder int
+
+const (
+	// BitOrderMSBFirst fills each byte from the most significant bit and
+	// stores the most significant bit of a field first. It is used, for
+	// example, in HDLC control fields and most bit-packed headers.
+	BitOrderMSBFirst BitOrder = iota
+	// BitOrderLSBFirst fills each byte from the least significant bit and
+	// stores the least significant bit of a field first, as in DEFLATE and
+	// serial line bit streams.
+	BitOrderLSBFirst
+)
+
+// BitOrderParse converts a bit order name to BitOrder.
+//
+// Accepted values are "MSBFirst" and "LSBFirst" (case-insensitive).
+//
+// It returns ErrUnknownEnum if value does not match a supported bit order.
+func BitOrderParse(value string) (BitOrder, error) {
+	var ret BitOrder
+	var err error
+	switch {
+	case strings.EqualFold(value, "MSBFirst"):
+		ret = BitOrderMSBFirst
+	case strings.EqualFold(value, "LSBFirst"):
+		ret = BitOrderLSBFirst
+	default:
+		err = fmt.Errorf("%w: %q", ErrUnknownEnum, value)
+	}
+	return ret, err
+}
+
+// String returns the canonical bit order name.
+//
+// It returns an empty string if g is not a defined BitOrder value.
+// String satisfies fmt.Stringer.
+func (g BitOrder) String() string {
+	var ret string
+	switch g {
+	case BitOrderMSBFirst:
+		ret = "MSBFirst"
+	case BitOrderLSBFirst:
+		ret = "LSBFirst"
+	}
+	return ret
+}
+
+// AllBitOrder returns all defined BitOrder values in declaration order.
+func AllBitOrder() []BitOrder {
+	return []BitOrder{
+		BitOrderMSBFirst,
+		BitOrderLSBFirst,
+	}
+}
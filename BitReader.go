@@ -0,0 +1,157 @@
+package gxcommon
+
+// --------------------------------------------------------------------------
+//
+//	Gurux Ltd
+//
+// Filename:        $HeadURL$
+//
+// Version:         $Revision$,
+//
+//	$Date$
+//	$Author$
+//
+// # Copyright (c) Gurux Ltd
+//
+// ---------------------------------------------------------------------------
+//
+//	DESCRIPTION
+//
+// This file is a part of Gurux Device Framework.
+//
+// Gurux Device Framework is Open Source software; you can redistribute it
+// and/or modify it under the terms of the GNU General Public License
+// as published by the Free Software Foundation; version 2 of the License.
+// Gurux Device Framework is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.
+// See the GNU General Public License for more details.
+//
+// More information of Gurux products: https://www.gurux.org
+//
+// This code is licensed under the GNU General Public License v2.
+// Full text may be retrieved at http://www.gnu.org/licenses/gpl-2.0.txt
+// ---------------------------------------------------------------------------
+
+// BitReader reads fields of arbitrary bit width from a byte slice.
+//
+// Fields are read in the BitOrder of the reader and do not need to be
+// aligned to bytes. Byte aligned data read with ReadBytes can be converted
+// with BytesToAny. Reads past the end of the data return
+// ErrBufferTooSmall and leave the reader unchanged.
+type BitReader struct {
+	data  []byte
+	pos   int
+	order BitOrder
+}
+
+// NewBitReader returns a reader for data.
+//
+// The reader does not copy data.
+func NewBitReader(data []byte, order BitOrder) *BitReader {
+	return &BitReader{data: data, order: order}
+}
+
+// BitOrder returns the bit order of the reader.
+func (r *BitReader) BitOrder() BitOrder {
+	return r.order
+}
+
+// Position returns the read position in bits.
+func (r *BitReader) Position() int {
+	return r.pos
+}
+
+// SetPosition sets the read position in bits.
+//
+// It returns ErrArgumentOutOfRange if value is negative or past the end of
+// the data.
+func (r *BitReader) SetPosition(value int) error {
+	if value < 0 || value > len(r.data)*8 {
+		return ErrArgumentOutOfRangeError("position")
+	}
+	r.pos = value
+	return nil
+}
+
+// Remaining returns the number of unread bits.
+func (r *BitReader) Remaining() int {
+	return len(r.data)*8 - r.pos
+}
+
+// Align skips the bits up to the next byte boundary.
+func (r *BitReader) Align() {
+	r.pos = (r.pos + 7) / 8 * 8
+}
+
+// ReadBits reads an unsigned field of n bits.
+//
+// n must be between 0 and 64.
+func (r *BitReader) ReadBits(n int) (uint64, error) {
+	if n < 0 || n > 64 {
+		return 0, ErrInvalidArgumentError("n")
+	}
+	if n > r.Remaining() {
+		return 0, ErrBufferTooSmallError("ReadBits")
+	}
+	var ret uint64
+	for i := 0; i < n; {
+		offset := r.pos % 8
+		count := min(8-offset, n-i)
+		mask := byte(1<<count - 1)
+		b := r.data[r.pos/8]
+		if r.order == BitOrderLSBFirst {
+			ret |= uint64(b>>offset&mask) << i
+		} else {
+			ret = ret<<count | uint64(b>>(8-offset-count)&mask)
+		}
+		r.pos += count
+		i += count
+	}
+	return ret, nil
+}
+
+// ReadSigned reads a two's complement signed field of n bits.
+//
+// n must be between 0 and 64.
+func (r *BitReader) ReadSigned(n int) (int64, error) {
+	value, err := r.ReadBits(n)
+	if err != nil {
+		return 0, err
+	}
+	if n > 0 && n < 64 && value&(1<<(n-1)) != 0 {
+		// Extend the sign bit.
+		value |= ^uint64(0) << n
+	}
+	return int64(value), nil
+}
+
+// ReadBool reads one bit.
+func (r *BitReader) ReadBool() (bool, error) {
+	value, err := r.ReadBits(1)
+	return value != 0, err
+}
+
+// ReadBytes reads count bytes of 8 bits.
+//
+// If the reader is aligned to a byte boundary, the returned slice refers to
+// the data of the reader.
+func (r *BitReader) ReadBytes(count int) ([]byte, error) {
+	if count < 0 {
+		return nil, ErrInvalidArgumentError("count")
+	}
+	if count*8 > r.Remaining() {
+		return nil, ErrBufferTooSmallError("ReadBytes")
+	}
+	if r.pos%8 == 0 {
+		start := r.pos / 8
+		r.pos += count * 8
+		return r.data[start : start+count : start+count], nil
+	}
+	ret := make([]byte, count)
+	for i := range ret {
+		value, _ := r.ReadBits(8)
+		ret[i] = byte(value)
+	}
+	return ret, nil
+}
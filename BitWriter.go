@@ -0,0 +1,150 @@
+package gxcommon
+
+// --------------------------------------------------------------------------
+//
+//	Gurux Ltd
+//
+// Filename:        $HeadURL$
+//
+// Version:         $Revision$,
+//
+//	$Date$
+//	$Author$
+//
+// # Copyright (c) Gurux Ltd
+//
+// ---------------------------------------------------------------------------
+//
+//	DESCRIPTION
+//
+// This file is a part of Gurux Device Framework.
+//
+// Gurux Device Framework is Open Source software; you can redistribute it
+// and/or modify it under the terms of the GNU General Public License
+// as published by the Free Software Foundation; version 2 of the License.
+// Gurux Device Framework is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.
+// See the GNU General Public License for more details.
+//
+// More information of Gurux products: https://www.gurux.org
+//
+// This code is licensed under the GNU General Public License v2.
+// Full text may be retrieved at http://www.gnu.org/licenses/gpl-2.0.txt
+// ---------------------------------------------------------------------------
+
+// BitWriter writes fields of arbitrary bit width to a growing byte slice.
+//
+// Fields are written in the BitOrder of the writer and do not need to be
+// aligned to bytes. Values converted with ToBytes can be written with
+// WriteBytes. Unused bits of the last byte are zero.
+//
+// The zero value is an empty MSB-first writer.
+type BitWriter struct {
+	data  []byte
+	size  int
+	order BitOrder
+}
+
+// NewBitWriter returns an empty writer.
+func NewBitWriter(order BitOrder) *BitWriter {
+	return &BitWriter{order: order}
+}
+
+// BitOrder returns the bit order of the writer.
+func (w *BitWriter) BitOrder() BitOrder {
+	return w.order
+}
+
+// Len returns the number of written bits.
+func (w *BitWriter) Len() int {
+	return w.size
+}
+
+// Bytes returns the written data. The last byte is padded with zero bits.
+//
+// The returned slice is valid until the next write.
+func (w *BitWriter) Bytes() []byte {
+	return w.data
+}
+
+// Reset removes the written data.
+func (w *BitWriter) Reset() {
+	w.data = w.data[:0]
+	w.size = 0
+}
+
+// Align pads the data with zero bits up to the next byte boundary.
+func (w *BitWriter) Align() {
+	w.size = len(w.data) * 8
+}
+
+// WriteBits writes value as an unsigned field of n bits.
+//
+// n must be between 0 and 64. It returns ErrArgumentOutOfRange if value
+// does not fit to n bits.
+func (w *BitWriter) WriteBits(value uint64, n int) error {
+	if n < 0 || n > 64 {
+		return ErrInvalidArgumentError("n")
+	}
+	if n < 64 && value>>n != 0 {
+		return ErrArgumentOutOfRangeError("value")
+	}
+	for i := 0; i < n; {
+		offset := w.size % 8
+		if offset == 0 {
+			w.data = append(w.data, 0)
+		}
+		count := min(8-offset, n-i)
+		mask := byte(1<<count - 1)
+		if w.order == BitOrderLSBFirst {
+			w.data[len(w.data)-1] |= byte(value>>i) & mask << offset
+		} else {
+			w.data[len(w.data)-1] |= byte(value>>(n-i-count)) & mask << (8 - offset - count)
+		}
+		w.size += count
+		i += count
+	}
+	return nil
+}
+
+// WriteSigned writes value as a two's complement signed field of n bits.
+//
+// n must be between 0 and 64. It returns ErrArgumentOutOfRange if value
+// does not fit to n bits.
+func (w *BitWriter) WriteSigned(value int64, n int) error {
+	if n < 0 || n > 64 {
+		return ErrInvalidArgumentError("n")
+	}
+	if n < 64 {
+		if n == 0 && value != 0 || n != 0 && (value < -1<<(n-1) || value > 1<<(n-1)-1) {
+			return ErrArgumentOutOfRangeError("value")
+		}
+		value &= 1<<n - 1
+	}
+	return w.WriteBits(uint64(value), n)
+}
+
+// WriteBool writes value as one bit.
+func (w *BitWriter) WriteBool(value bool) error {
+	var bit uint64
+	if value {
+		bit = 1
+	}
+	return w.WriteBits(bit, 1)
+}
+
+// WriteBytes writes data as bytes of 8 bits.
+func (w *BitWriter) WriteBytes(data []byte) error {
+	if w.size%8 == 0 {
+		w.data = append(w.data, data...)
+		w.size = len(w.data) * 8
+		return nil
+	}
+	for _, it := range data {
+		if err := w.WriteBits(uint64(it), 8); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,219 @@
+package gxcommon
+
+// --------------------------------------------------------------------------
+//
+//	Gurux Ltd
+//
+// Filename:        $HeadURL$
+//
+// Version:         $Revision$,
+//
+//	$Date$
+//	$Author$
+//
+// # Copyright (c) Gurux Ltd
+//
+// ---------------------------------------------------------------------------
+//
+//	DESCRIPTION
+//
+// This file is a part of Gurux Device Framework.
+//
+// Gurux Device Framework is Open Source software; you can redistribute it
+// and/or modify it under the terms of the GNU General Public License
+// as published by the Free Software Foundation; version 2 of the License.
+// Gurux Device Framework is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.
+// See the GNU General Public License for more details.
+//
+// More information of Gurux products: https://www.gurux.org
+//
+// This code is licensed under the GNU General Public License v2.
+// Full text may be retrieved at http://www.gnu.org/licenses/gpl-2.0.txt
+// ---------------------------------------------------------------------------
+
+import (
+	"encoding/binary"
+	"fmt"
+	"strings"
+)
+
+// WordSwappedBigEndian is the mixed-endian byte order where 16-bit words are
+// big-endian and the least significant word is first (CDAB).
+//
+// The uint32 value 0xAABBCCDD is stored as CC DD AA BB. It is common in
+// Modbus devices that store 32-bit and 64-bit values in several registers.
+var WordSwappedBigEndian wordSwappedBigEndian
+
+// WordSwappedLittleEndian is the mixed-endian byte order where 16-bit words
+// are little-endian and the most significant word is first (BADC).
+//
+// The uint32 value 0xAABBCCDD is stored as BB AA DD CC.
+var WordSwappedLittleEndian wordSwappedLittleEndian
+
+// wordSwappedBigEndian implements WordSwappedBigEndian.
+type wordSwappedBigEndian struct{}
+
+// Uint16 returns the big-endian uint16 in b.
+func (wordSwappedBigEndian) Uint16(b []byte) uint16 {
+	return binary.BigEndian.Uint16(b)
+}
+
+// PutUint16 stores v to b in big-endian byte order.
+func (wordSwappedBigEndian) PutUint16(b []byte, v uint16) {
+	binary.BigEndian.PutUint16(b, v)
+}
+
+// AppendUint16 appends v to b in big-endian byte order.
+func (wordSwappedBigEndian) AppendUint16(b []byte, v uint16) []byte {
+	return binary.BigEndian.AppendUint16(b, v)
+}
+
+// Uint32 returns the uint32 stored in b.
+func (wordSwappedBigEndian) Uint32(b []byte) uint32 {
+	_ = b[3]
+	return uint32(b[1]) | uint32(b[0])<<8 | uint32(b[3])<<16 | uint32(b[2])<<24
+}
+
+// PutUint32 stores v to b.
+func (wordSwappedBigEndian) PutUint32(b []byte, v uint32) {
+	_ = b[3]
+	b[0] = byte(v >> 8)
+	b[1] = byte(v)
+	b[2] = byte(v >> 24)
+	b[3] = byte(v >> 16)
+}
+
+// AppendUint32 appends v to b.
+func (wordSwappedBigEndian) AppendUint32(b []byte, v uint32) []byte {
+	return append(b, byte(v>>8), byte(v), byte(v>>24), byte(v>>16))
+}
+
+// Uint64 returns the uint64 stored in b.
+func (o wordSwappedBigEndian) Uint64(b []byte) uint64 {
+	_ = b[7]
+	return uint64(o.Uint32(b)) | uint64(o.Uint32(b[4:]))<<32
+}
+
+// PutUint64 stores v to b.
+func (o wordSwappedBigEndian) PutUint64(b []byte, v uint64) {
+	_ = b[7]
+	o.PutUint32(b, uint32(v))
+	o.PutUint32(b[4:], uint32(v>>32))
+}
+
+// AppendUint64 appends v to b.
+func (o wordSwappedBigEndian) AppendUint64(b []byte, v uint64) []byte {
+	return o.AppendUint32(o.AppendUint32(b, uint32(v)), uint32(v>>32))
+}
+
+// String returns the name of the byte order.
+func (wordSwappedBigEndian) String() string {
+	return "WordSwappedBigEndian"
+}
+
+// GoString returns the Go syntax of the byte order.
+func (wordSwappedBigEndian) GoString() string {
+	return "gxcommon.WordSwappedBigEndian"
+}
+
+// wordSwappedLittleEndian implements WordSwappedLittleEndian.
+type wordSwappedLittleEndian struct{}
+
+// Uint16 returns the little-endian uint16 in b.
+func (wordSwappedLittleEndian) Uint16(b []byte) uint16 {
+	return binary.LittleEndian.Uint16(b)
+}
+
+// PutUint16 stores v to b in little-endian byte order.
+func (wordSwappedLittleEndian) PutUint16(b []byte, v uint16) {
+	binary.LittleEndian.PutUint16(b, v)
+}
+
+// AppendUint16 appends v to b in little-endian byte order.
+func (wordSwappedLittleEndian) AppendUint16(b []byte, v uint16) []byte {
+	return binary.LittleEndian.AppendUint16(b, v)
+}
+
+// Uint32 returns the uint32 stored in b.
+func (wordSwappedLittleEndian) Uint32(b []byte) uint32 {
+	_ = b[3]
+	return uint32(b[2]) | uint32(b[3])<<8 | uint32(b[0])<<16 | uint32(b[1])<<24
+}
+
+// PutUint32 stores v to b.
+func (wordSwappedLittleEndian) PutUint32(b []byte, v uint32) {
+	_ = b[3]
+	b[0] = byte(v >> 16)
+	b[1] = byte(v >> 24)
+	b[2] = byte(v)
+	b[3] = byte(v >> 8)
+}
+
+// AppendUint32 appends v to b.
+func (wordSwappedLittleEndian) AppendUint32(b []byte, v uint32) []byte {
+	return append(b, byte(v>>16), byte(v>>24), byte(v), byte(v>>8))
+}
+
+// Uint64 returns the uint64 stored in b.
+func (o wordSwappedLittleEndian) Uint64(b []byte) uint64 {
+	_ = b[7]
+	return uint64(o.Uint32(b))<<32 | uint64(o.Uint32(b[4:]))
+}
+
+// PutUint64 stores v to b.
+func (o wordSwappedLittleEndian) PutUint64(b []byte, v uint64) {
+	_ = b[7]
+	o.PutUint32(b, uint32(v>>32))
+	o.PutUint32(b[4:], uint32(v))
+}
+
+// AppendUint64 appends v to b.
+func (o wordSwappedLittleEndian) AppendUint64(b []byte, v uint64) []byte {
+	return o.AppendUint32(o.AppendUint32(b, uint32(v>>32)), uint32(v))
+}
+
+// String returns the name of the byte order.
+func (wordSwappedLittleEndian) String() string {
+	return "WordSwappedLittleEndian"
+}
+
+// GoString returns the Go syntax of the byte order.
+func (wordSwappedLittleEndian) GoString() string {
+	return "gxcommon.WordSwappedLittleEndian"
+}
+
+// ByteOrderParse converts a byte order name to a byte order.
+//
+// The name is the String value of the byte order or the layout of the
+// uint32 value 0xAABBCCDD in memory, case-insensitively:
+// BigEndian (ABCD), LittleEndian (DCBA), WordSwappedBigEndian (CDAB) and
+// WordSwappedLittleEndian (BADC).
+func ByteOrderParse(value string) (binary.ByteOrder, error) {
+	var ret binary.ByteOrder
+	var err error
+	switch {
+	case strings.EqualFold(value, "BigEndian"), strings.EqualFold(value, "ABCD"):
+		ret = binary.BigEndian
+	case strings.EqualFold(value, "LittleEndian"), strings.EqualFold(value, "DCBA"):
+		ret = binary.LittleEndian
+	case strings.EqualFold(value, "WordSwappedBigEndian"), strings.EqualFold(value, "CDAB"):
+		ret = WordSwappedBigEndian
+	case strings.EqualFold(value, "WordSwappedLittleEndian"), strings.EqualFold(value, "BADC"):
+		ret = WordSwappedLittleEndian
+	default:
+		err = fmt.Errorf("%w: %q", ErrUnknownEnum, value)
+	}
+	return ret, err
+}
+
+// AllByteOrders returns the byte orders accepted by ByteOrderParse.
+func AllByteOrders() []binary.ByteOrder {
+	return []binary.ByteOrder{
+		binary.BigEndian,
+		binary.LittleEndian,
+		WordSwappedBigEndian,
+		WordSwappedLittleEndian,
+	}
+}
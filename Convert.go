@@ -0,0 +1,322 @@
+package gxcommon
+
+// --------------------------------------------------------------------------
+//
+//	Gurux Ltd
+//
+// Filename:        $HeadURL$
+//
+// Version:         $Revision$,
+//
+//	$Date$
+//	$Author$
+//
+// # Copyright (c) Gurux Ltd
+//
+// ---------------------------------------------------------------------------
+//
+//	DESCRIPTION
+//
+// This file is a part of Gurux Device Framework.
+//
+// Gurux Device Framework is Open Source software; you can redistribute it
+// and/or modify it under the terms of the GNU General Public License
+// as published by the Free Software Foundation; version 2 of the License.
+// Gurux Device Framework is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.
+// See the GNU General Public License for more details.
+//
+// More information of Gurux products: https://www.gurux.org
+//
+// This code is licensed under the GNU General Public License v2.
+// Full text may be retrieved at http://www.gnu.org/licenses/gpl-2.0.txt
+// ---------------------------------------------------------------------------
+
+import (
+	"bytes"
+	"encoding/binary"
+	"fmt"
+	"io"
+	"math"
+	"reflect"
+)
+
+// AppendBytes appends v to dst using the given byte order and returns the
+// extended slice.
+//
+// It supports the same types as ToBytes except arrays. Fixed-size values
+// are written with the methods of order without allocating when dst has
+// enough capacity. A nil v appends nothing.
+func AppendBytes(dst []byte, v any, order binary.ByteOrder) ([]byte, error) {
+	switch x := v.(type) {
+	case nil:
+		return dst, nil
+	case []byte:
+		return append(dst, x...), nil
+	case string:
+		return append(dst, x...), nil
+	case uint8:
+		return append(dst, x), nil
+	case int8:
+		return append(dst, byte(x)), nil
+	case bool:
+		if x {
+			return append(dst, 1), nil
+		}
+		return append(dst, 0), nil
+	case int16:
+		return appendUint16(dst, uint16(x), order), nil
+	case uint16:
+		return appendUint16(dst, x, order), nil
+	case int32:
+		return appendUint32(dst, uint32(x), order), nil
+	case uint32:
+		return appendUint32(dst, x, order), nil
+	case float32:
+		return appendUint32(dst, math.Float32bits(x), order), nil
+	case int64:
+		return appendUint64(dst, uint64(x), order), nil
+	case uint64:
+		return appendUint64(dst, x, order), nil
+	case int:
+		return appendUint64(dst, uint64(x), order), nil
+	case uint:
+		return appendUint64(dst, uint64(x), order), nil
+	case float64:
+		return appendUint64(dst, math.Float64bits(x), order), nil
+	case []int8:
+		for _, it := range x {
+			dst = append(dst, byte(it))
+		}
+		return dst, nil
+	case []int16:
+		for _, it := range x {
+			dst = appendUint16(dst, uint16(it), order)
+		}
+		return dst, nil
+	case []uint16:
+		for _, it := range x {
+			dst = appendUint16(dst, it, order)
+		}
+		return dst, nil
+	case []int32:
+		for _, it := range x {
+			dst = appendUint32(dst, uint32(it), order)
+		}
+		return dst, nil
+	case []uint32:
+		for _, it := range x {
+			dst = appendUint32(dst, it, order)
+		}
+		return dst, nil
+	case []float32:
+		for _, it := range x {
+			dst = appendUint32(dst, math.Float32bits(it), order)
+		}
+		return dst, nil
+	case []int64:
+		for _, it := range x {
+			dst = appendUint64(dst, uint64(it), order)
+		}
+		return dst, nil
+	case []uint64:
+		for _, it := range x {
+			dst = appendUint64(dst, it, order)
+		}
+		return dst, nil
+	case []float64:
+		for _, it := range x {
+			dst = appendUint64(dst, math.Float64bits(it), order)
+		}
+		return dst, nil
+	}
+	return nil, ErrInvalidArgumentError("unsupported type " + reflect.TypeOf(v).String())
+}
+
+// DecodeInto decodes b to the value that out points to using the given byte
+// order and returns the number of bytes used.
+//
+// out must be a pointer to a type supported by AppendBytes. Fixed-size values
+// are read from the beginning of b without allocating. Strings, byte slices
+// and other slices are decoded from all of b, which must be a multiple of the
+// element size. int and uint are read from 8 bytes and bool is true if the
+// byte is not zero.
+//
+// It returns ErrBufferTooSmall, which also matches io.ErrUnexpectedEOF, if b
+// is too short, ErrArgumentOutOfRange if an int or uint does not fit, and
+// ErrInvalidArgument if the type of out is not supported.
+func DecodeInto(b []byte, out any, order binary.ByteOrder) (int, error) {
+	switch x := out.(type) {
+	case *string:
+		*x = string(b)
+		return len(b), nil
+	case *[]byte:
+		*x = bytes.Clone(b)
+		if *x == nil {
+			*x = []byte{}
+		}
+		return len(b), nil
+	case *uint8:
+		if len(b) < 1 {
+			return 0, errShort("uint8")
+		}
+		*x = b[0]
+		return 1, nil
+	case *int8:
+		if len(b) < 1 {
+			return 0, errShort("int8")
+		}
+		*x = int8(b[0])
+		return 1, nil
+	case *bool:
+		if len(b) < 1 {
+			return 0, errShort("bool")
+		}
+		*x = b[0] != 0
+		return 1, nil
+	case *int16:
+		if len(b) < 2 {
+			return 0, errShort("int16")
+		}
+		*x = int16(order.Uint16(b))
+		return 2, nil
+	case *uint16:
+		if len(b) < 2 {
+			return 0, errShort("uint16")
+		}
+		*x = order.Uint16(b)
+		return 2, nil
+	case *int32:
+		if len(b) < 4 {
+			return 0, errShort("int32")
+		}
+		*x = int32(order.Uint32(b))
+		return 4, nil
+	case *uint32:
+		if len(b) < 4 {
+			return 0, errShort("uint32")
+		}
+		*x = order.Uint32(b)
+		return 4, nil
+	case *float32:
+		if len(b) < 4 {
+			return 0, errShort("float32")
+		}
+		*x = math.Float32frombits(order.Uint32(b))
+		return 4, nil
+	case *int64:
+		if len(b) < 8 {
+			return 0, errShort("int64")
+		}
+		*x = int64(order.Uint64(b))
+		return 8, nil
+	case *uint64:
+		if len(b) < 8 {
+			return 0, errShort("uint64")
+		}
+		*x = order.Uint64(b)
+		return 8, nil
+	case *float64:
+		if len(b) < 8 {
+			return 0, errShort("float64")
+		}
+		*x = math.Float64frombits(order.Uint64(b))
+		return 8, nil
+	case *int:
+		if len(b) < 8 {
+			return 0, errShort("int")
+		}
+		v := int64(order.Uint64(b))
+		if int64(int(v)) != v {
+			return 0, ErrArgumentOutOfRangeError("int")
+		}
+		*x = int(v)
+		return 8, nil
+	case *uint:
+		if len(b) < 8 {
+			return 0, errShort("uint")
+		}
+		v := order.Uint64(b)
+		if uint64(uint(v)) != v {
+			return 0, ErrArgumentOutOfRangeError("uint")
+		}
+		*x = uint(v)
+		return 8, nil
+	case *[]int8:
+		*x = make([]int8, len(b))
+		for i, it := range b {
+			(*x)[i] = int8(it)
+		}
+		return len(b), nil
+	case *[]int16:
+		return decodeSlice(b, x, 2, func(d []byte) int16 { return int16(order.Uint16(d)) })
+	case *[]uint16:
+		return decodeSlice(b, x, 2, order.Uint16)
+	case *[]int32:
+		return decodeSlice(b, x, 4, func(d []byte) int32 { return int32(order.Uint32(d)) })
+	case *[]uint32:
+		return decodeSlice(b, x, 4, order.Uint32)
+	case *[]float32:
+		return decodeSlice(b, x, 4, func(d []byte) float32 { return math.Float32frombits(order.Uint32(d)) })
+	case *[]int64:
+		return decodeSlice(b, x, 8, func(d []byte) int64 { return int64(order.Uint64(d)) })
+	case *[]uint64:
+		return decodeSlice(b, x, 8, order.Uint64)
+	case *[]float64:
+		return decodeSlice(b, x, 8, func(d []byte) float64 { return math.Float64frombits(order.Uint64(d)) })
+	}
+	if out == nil {
+		return 0, ErrInvalidArgumentError("out")
+	}
+	return 0, ErrInvalidArgumentError("unsupported type " + reflect.TypeOf(out).String())
+}
+
+// decodeSlice decodes all of b to a slice of size byte elements.
+func decodeSlice[E any](b []byte, out *[]E, size int, decode func([]byte) E) (int, error) {
+	if len(b)%size != 0 {
+		return 0, ErrInvalidArgumentError(fmt.Sprintf("%d bytes is not a multiple of %d", len(b), size))
+	}
+	ret := make([]E, len(b)/size)
+	for i := range ret {
+		ret[i] = decode(b[i*size:])
+	}
+	*out = ret
+	return len(b), nil
+}
+
+// errShort returns an error for a buffer that is too short for a value of
+// the named type. It matches both ErrBufferTooSmall and io.ErrUnexpectedEOF.
+func errShort(name string) error {
+	return fmt.Errorf("%w: %s: %w", ErrBufferTooSmall, name, io.ErrUnexpectedEOF)
+}
+
+// appendUint16 appends v to dst in the given byte order.
+func appendUint16(dst []byte, v uint16, order binary.ByteOrder) []byte {
+	if a, ok := order.(binary.AppendByteOrder); ok {
+		return a.AppendUint16(dst, v)
+	}
+	dst = append(dst, 0, 0)
+	order.PutUint16(dst[len(dst)-2:], v)
+	return dst
+}
+
+// appendUint32 appends v to dst in the given byte order.
+func appendUint32(dst []byte, v uint32, order binary.ByteOrder) []byte {
+	if a, ok := order.(binary.AppendByteOrder); ok {
+		return a.AppendUint32(dst, v)
+	}
+	dst = append(dst, 0, 0, 0, 0)
+	order.PutUint32(dst[len(dst)-4:], v)
+	return dst
+}
+
+// appendUint64 appends v to dst in the given byte order.
+func appendUint64(dst []byte, v uint64, order binary.ByteOrder) []byte {
+	if a, ok := order.(binary.AppendByteOrder); ok {
+		return a.AppendUint64(dst, v)
+	}
+	dst = append(dst, 0, 0, 0, 0, 0, 0, 0, 0)
+	order.PutUint64(dst[len(dst)-8:], v)
+	return dst
+}
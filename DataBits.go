@@ -0,0 +1,106 @@
+package gxcommon
+
+// --------------------------------------------------------------------------
+//
+//	Gurux Ltd
+//
+// Filename:        $HeadURL$
+//
+// Version:         $Revision$,
+//
+//	$Date$
+//	$Author$
+//
+// # Copyright (c) Gurux Ltd
+//
+// ---------------------------------------------------------------------------
+//
+//	DESCRIPTION
+//
+// This file is a part of Gurux Device Framework.
+//
+// Gurux Device Framework is Open Source software; you can redistribute it
+// and/or modify it under the terms of the GNU General Public License
+// as published by the Free Software Foundation; version 2 of the License.
+// Gurux Device Framework is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.
+// See the GNU General Public License for more details.
+//
+// More information of Gurux products: https://www.gurux.org
+//
+// This code is licensed under the GNU General Public License v2.
+// Full text may be retrieved at http://www.gnu.org/licenses/gpl-2.0.txt
+// ---------------------------------------------------------------------------
+
+import (
+	"fmt"
+	"strings"
+)
+
+// DataBits defines the number of data bits in a serial character.
+// The underlying value of DataBits is the number of bits.
+type DataBits int
+
+const (
+	// DataBits5 is 5 data bits.
+	DataBits5 DataBits = 5
+	// DataBits6 is 6 data bits.
+	DataBits6 DataBits = 6
+	// DataBits7 is 7 data bits.
+	DataBits7 DataBits = 7
+	// DataBits8 is 8 data bits.
+	DataBits8 DataBits = 8
+)
+
+// DataBitsParse converts the given string into a DataBits value.
+//
+// Accepted values are "5", "6", "7", and "8".
+//
+// It returns ErrUnknownEnum if value does not match a supported value.
+func DataBitsParse(value string) (DataBits, error) {
+	var ret DataBits
+	var err error
+	switch strings.TrimSpace(value) {
+	case "5":
+		ret = DataBits5
+	case "6":
+		ret = DataBits6
+	case "7":
+		ret = DataBits7
+	case "8":
+		ret = DataBits8
+	default:
+		err = fmt.Errorf("%w: %q", ErrUnknownEnum, value)
+	}
+	return ret, err
+}
+
+// String returns the decimal number of data bits.
+//
+// It returns an empty string if g is not a defined DataBits value.
+// String satisfies fmt.Stringer.
+func (g DataBits) String() string {
+	var ret string
+	switch g {
+	case DataBits5:
+		ret = "5"
+	case DataBits6:
+		ret = "6"
+	case DataBits7:
+		ret = "7"
+	case DataBits8:
+		ret = "8"
+	}
+	return ret
+}
+
+// AllDataBits returns all defined DataBits values in declaration order.
+func AllDataBits() []DataBits {
+	return []DataBits{
+		DataBits5,
+		DataBits6,
+		DataBits7,
+		DataBits8,
+	}
+}
@@ -0,0 +1,386 @@
+package gxcommon
+
+// --------------------------------------------------------------------------
+//
+//	Gurux Ltd
+//
+// Filename:        $HeadURL$
+//
+// Version:         $Revision$,
+//
+//	$Date$
+//	$Author$
+//
+// # Copyright (c) Gurux Ltd
+//
+// ---------------------------------------------------------------------------
+//
+//	DESCRIPTION
+//
+// This file is a part of Gurux Device Framework.
+//
+// Gurux Device Framework is Open Source software; you can redistribute it
+// and/or modify it under the terms of the GNU General Public License
+// as published by the Free Software Foundation; version 2 of the License.
+// Gurux Device Framework is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.
+// See the GNU General Public License for more details.
+//
+// More information of Gurux products: https://www.gurux.org
+//
+// This code is licensed under the GNU General Public License v2.
+// Full text may be retrieved at http://www.gnu.org/licenses/gpl-2.0.txt
+// ---------------------------------------------------------------------------
+
+import (
+	"bytes"
+	"encoding/binary"
+)
+
+// Framer detects frame boundaries in a byte stream.
+//
+// A framer is stateless, so the same framer can be used with several
+// streams.
+type Framer interface {
+	// Next returns the first frame of data and the number of bytes it used.
+	//
+	// If data does not start with a complete frame, frame is nil and n is
+	// the number of leading bytes that can not belong to a frame and should
+	// be discarded, or zero if more data is needed. A malformed frame is
+	// reported with an error and n is the number of bytes to discard.
+	Next(data []byte) (frame []byte, n int, err error)
+
+	// Encode returns payload as a frame.
+	Encode(payload []byte) ([]byte, error)
+}
+
+// EOPFramer is a framer for frames that end with an end-of-packet marker.
+//
+// The frame includes the marker, like a reply received with
+// ReceiveParameters.EOP.
+type EOPFramer struct {
+	// EOP is the end-of-packet marker.
+	EOP []byte
+}
+
+// Next returns the data up to and including the first EOP.
+func (f *EOPFramer) Next(data []byte) ([]byte, int, error) {
+	if len(f.EOP) == 0 {
+		return nil, 0, ErrInvalidArgumentError("EOP")
+	}
+	pos := bytes.Index(data, f.EOP)
+	if pos == -1 {
+		return nil, 0, nil
+	}
+	pos += len(f.EOP)
+	return data[:pos], pos, nil
+}
+
+// Encode appends EOP to payload.
+func (f *EOPFramer) Encode(payload []byte) ([]byte, error) {
+	if len(f.EOP) == 0 {
+		return nil, ErrInvalidArgumentError("EOP")
+	}
+	ret := make([]byte, 0, len(payload)+len(f.EOP))
+	ret = append(ret, payload...)
+	return append(ret, f.EOP...), nil
+}
+
+// LengthPrefixFramer is a framer for frames that start with the payload
+// length.
+//
+// The frame is the payload without the length.
+type LengthPrefixFramer struct {
+	// Size is the size of the length in bytes: 1, 2 or 4.
+	Size int
+
+	// Order is the byte order of the length. Big-endian is used if Order
+	// is nil.
+	Order binary.ByteOrder
+
+	// IncludeLength tells whether the length includes the length field
+	// itself.
+	IncludeLength bool
+
+	// MaxLength is the maximum payload length. A longer length is reported
+	// as corrupt, so the stream is resynchronized instead of waiting for
+	// data that never arrives. Zero means no limit.
+	MaxLength int
+}
+
+// Next returns the payload of the first frame.
+func (f *LengthPrefixFramer) Next(data []byte) ([]byte, int, error) {
+	if f.Size != 1 && f.Size != 2 && f.Size != 4 {
+		return nil, 0, ErrInvalidArgumentError("Size")
+	}
+	if len(data) < f.Size {
+		return nil, 0, nil
+	}
+	order := f.order()
+	var size uint64
+	switch f.Size {
+	case 1:
+		size = uint64(data[0])
+	case 2:
+		size = uint64(order.Uint16(data))
+	default:
+		size = uint64(order.Uint32(data))
+	}
+	if f.IncludeLength {
+		if size < uint64(f.Size) {
+			return nil, f.Size, ErrInvalidArgumentError("length")
+		}
+		size -= uint64(f.Size)
+	}
+	if f.MaxLength > 0 && size > uint64(f.MaxLength) {
+		return nil, f.Size, ErrArgumentOutOfRangeError("length")
+	}
+	if uint64(len(data)-f.Size) < size {
+		return nil, 0, nil
+	}
+	end := f.Size + int(size)
+	return data[f.Size:end], end, nil
+}
+
+// Encode prepends the length to payload.
+func (f *LengthPrefixFramer) Encode(payload []byte) ([]byte, error) {
+	if f.Size != 1 && f.Size != 2 && f.Size != 4 {
+		return nil, ErrInvalidArgumentError("Size")
+	}
+	if f.MaxLength > 0 && len(payload) > f.MaxLength {
+		return nil, ErrArgumentOutOfRangeError("payload")
+	}
+	size := uint64(len(payload))
+	if f.IncludeLength {
+		size += uint64(f.Size)
+	}
+	ret := make([]byte, f.Size, f.Size+len(payload))
+	order := f.order()
+	switch f.Size {
+	case 1:
+		if size > 0xFF {
+			return nil, ErrArgumentOutOfRangeError("payload")
+		}
+		ret[0] = byte(size)
+	case 2:
+		if size > 0xFFFF {
+			return nil, ErrArgumentOutOfRangeError("payload")
+		}
+		order.PutUint16(ret, uint16(size))
+	case 4:
+		if size > 0xFFFFFFFF {
+			return nil, ErrArgumentOutOfRangeError("payload")
+		}
+		order.PutUint32(ret, uint32(size))
+	default:
+		return nil, ErrInvalidArgumentError("Size")
+	}
+	return append(ret, payload...), nil
+}
+
+// order returns the byte order of the length.
+func (f *LengthPrefixFramer) order() binary.ByteOrder {
+	if f.Order == nil {
+		return binary.BigEndian
+	}
+	return f.Order
+}
+
+const (
+	// stx is the start of text character.
+	stx = 0x02
+	// etx is the end of text character.
+	etx = 0x03
+)
+
+// STXETXFramer is a framer for frames that start with STX (0x02) and end
+// with ETX (0x03), optionally followed by a block check character.
+//
+// The frame is the payload between STX and ETX. Bytes before STX are
+// discarded.
+type STXETXFramer struct {
+	// BCC tells whether ETX is followed by a block check character. BCC
+	// is the XOR of the bytes after STX up to and including ETX, as in
+	// IEC 62056-21.
+	BCC bool
+}
+
+// Next returns the payload of the first frame.
+func (f *STXETXFramer) Next(data []byte) ([]byte, int, error) {
+	start := bytes.IndexByte(data, stx)
+	if start == -1 {
+		return nil, len(data), nil
+	}
+	if start != 0 {
+		return nil, start, nil
+	}
+	end := bytes.IndexByte(data[1:], etx)
+	if end == -1 {
+		return nil, 0, nil
+	}
+	end++
+	n := end + 1
+	if f.BCC {
+		if len(data) == n {
+			return nil, 0, nil
+		}
+		n++
+		if bcc(data[1:end+1]) != data[end+1] {
+			return nil, n, ErrInvalidArgumentError("BCC")
+		}
+	}
+	return data[1:end], n, nil
+}
+
+// Encode adds STX, ETX and BCC to payload.
+//
+// It returns ErrInvalidArgument if payload contains ETX.
+func (f *STXETXFramer) Encode(payload []byte) ([]byte, error) {
+	if bytes.IndexByte(payload, etx) != -1 {
+		return nil, ErrInvalidArgumentError("payload")
+	}
+	ret := make([]byte, 0, len(payload)+3)
+	ret = append(ret, stx)
+	ret = append(ret, payload...)
+	ret = append(ret, etx)
+	if f.BCC {
+		ret = append(ret, bcc(ret[1:]))
+	}
+	return ret, nil
+}
+
+// bcc returns the XOR of data.
+func bcc(data []byte) byte {
+	var ret byte
+	for _, it := range data {
+		ret ^= it
+	}
+	return ret
+}
+
+const (
+	// slipEnd ends a SLIP frame.
+	slipEnd = 0xC0
+	// slipEsc starts a SLIP escape sequence.
+	slipEsc = 0xDB
+	// slipEscEnd is an escaped END.
+	slipEscEnd = 0xDC
+	// slipEscEsc is an escaped ESC.
+	slipEscEsc = 0xDD
+)
+
+// SLIPFramer is a framer for SLIP (RFC 1055) frames.
+//
+// The frame is the payload without escaping. Empty frames are discarded.
+type SLIPFramer struct{}
+
+// Next returns the payload of the first frame.
+func (f *SLIPFramer) Next(data []byte) ([]byte, int, error) {
+	end := bytes.IndexByte(data, slipEnd)
+	if end == -1 {
+		return nil, 0, nil
+	}
+	if end == 0 {
+		return nil, 1, nil
+	}
+	ret := make([]byte, 0, end)
+	for i := 0; i < end; i++ {
+		b := data[i]
+		if b == slipEsc {
+			i++
+			switch {
+			case i == end:
+				return nil, end + 1, ErrInvalidArgumentError("SLIP escape")
+			case data[i] == slipEscEnd:
+				b = slipEnd
+			case data[i] == slipEscEsc:
+				b = slipEsc
+			default:
+				return nil, end + 1, ErrInvalidArgumentError("SLIP escape")
+			}
+		}
+		ret = append(ret, b)
+	}
+	return ret, end + 1, nil
+}
+
+// Encode escapes payload and surrounds it with END characters.
+func (f *SLIPFramer) Encode(payload []byte) ([]byte, error) {
+	ret := make([]byte, 0, len(payload)+2)
+	ret = append(ret, slipEnd)
+	for _, it := range payload {
+		switch it {
+		case slipEnd:
+			ret = append(ret, slipEsc, slipEscEnd)
+		case slipEsc:
+			ret = append(ret, slipEsc, slipEscEsc)
+		default:
+			ret = append(ret, it)
+		}
+	}
+	return append(ret, slipEnd), nil
+}
+
+const (
+	// hdlcFlag is the HDLC frame delimiter.
+	hdlcFlag = 0x7E
+	// hdlcEscape is the HDLC control escape.
+	hdlcEscape = 0x7D
+	// hdlcXor is XORed with escaped bytes.
+	hdlcXor = 0x20
+)
+
+// HDLCFramer is a framer for HDLC frames that are delimited with 0x7E flags
+// and use byte stuffing (RFC 1662).
+//
+// The frame is the content between the flags without stuffing. The closing
+// flag can be the opening flag of the next frame. Bytes before the opening
+// flag and empty frames are discarded.
+type HDLCFramer struct{}
+
+// Next returns the content of the first frame.
+func (f *HDLCFramer) Next(data []byte) ([]byte, int, error) {
+	start := bytes.IndexByte(data, hdlcFlag)
+	if start == -1 {
+		return nil, len(data), nil
+	}
+	if start != 0 {
+		return nil, start, nil
+	}
+	end := bytes.IndexByte(data[1:], hdlcFlag)
+	if end == -1 {
+		return nil, 0, nil
+	}
+	end++
+	if end == 1 {
+		return nil, 1, nil
+	}
+	ret := make([]byte, 0, end-1)
+	for i := 1; i < end; i++ {
+		b := data[i]
+		if b == hdlcEscape {
+			i++
+			if i == end {
+				return nil, end, ErrInvalidArgumentError("HDLC escape")
+			}
+			b = data[i] ^ hdlcXor
+		}
+		ret = append(ret, b)
+	}
+	return ret, end, nil
+}
+
+// Encode stuffs payload and surrounds it with flags.
+func (f *HDLCFramer) Encode(payload []byte) ([]byte, error) {
+	ret := make([]byte, 0, len(payload)+2)
+	ret = append(ret, hdlcFlag)
+	for _, it := range payload {
+		if it == hdlcFlag || it == hdlcEscape {
+			ret = append(ret, hdlcEscape, it^hdlcXor)
+		} else {
+			ret = append(ret, it)
+		}
+	}
+	return append(ret, hdlcFlag), nil
+}
@@ -0,0 +1,647 @@
+package gxcommon
+
+// --------------------------------------------------------------------------
+//
+//	Gurux Ltd
+//
+// Filename:        $HeadURL$
+//
+// Version:         $Revision$,
+//
+//	$Date$
+//	$Author$
+//
+// # Copyright (c) Gurux Ltd
+//
+// ---------------------------------------------------------------------------
+//
+//	DESCRIPTION
+//
+// This file is a part of Gurux Device Framework.
+//
+// Gurux Device Framework is Open Source software; you can redistribute it
+// and/or modify it under the terms of the GNU General Public License
+// as published by the Free Software Foundation; version 2 of the License.
+// Gurux Device Framework is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.
+// See the GNU General Public License for more details.
+//
+// More information of Gurux products: https://www.gurux.org
+//
+// This code is licensed under the GNU General Public License v2.
+// Full text may be retrieved at http://www.gnu.org/licenses/gpl-2.0.txt
+// ---------------------------------------------------------------------------
+
+import (
+	"bytes"
+	"encoding/binary"
+	"encoding/hex"
+	"math"
+	"strings"
+	"unicode/utf8"
+)
+
+// GXByteBuffer is a byte buffer with a read position for protocol encoding
+// and decoding.
+//
+// Set methods append values to the end of the buffer, SetXxxAt methods
+// overwrite values at an index, and Get methods read values at the position
+// and move it forward. Multi-byte values use the
+// byte order of the buffer, which is big-endian by default. Get methods
+// return ErrBufferTooSmall if there is not enough data after the position;
+// the position is then left unchanged.
+//
+// The zero value is an empty buffer ready to use. GXByteBuffer is not safe
+// for concurrent use.
+type GXByteBuffer struct {
+	data     []byte
+	position int
+	order    binary.ByteOrder
+}
+
+// NewGXByteBuffer returns an empty buffer with the given capacity.
+func NewGXByteBuffer(capacity int) *GXByteBuffer {
+	return &GXByteBuffer{data: make([]byte, 0, capacity)}
+}
+
+// NewGXByteBufferFrom returns a buffer that holds data. The buffer uses data
+// as its storage until it grows.
+func NewGXByteBufferFrom(data []byte) *GXByteBuffer {
+	return &GXByteBuffer{data: data}
+}
+
+// NewGXByteBufferFromHex returns a buffer that holds the bytes of a hex
+// string, such as one returned by ToHex.
+//
+// It returns ErrInvalidArgument if value is not valid hex.
+func NewGXByteBufferFromHex(value string) (*GXByteBuffer, error) {
+	ret := &GXByteBuffer{}
+	if err := ret.SetHex(value); err != nil {
+		return nil, err
+	}
+	return ret, nil
+}
+
+// ByteOrder returns the byte order of multi-byte values.
+func (b *GXByteBuffer) ByteOrder() binary.ByteOrder {
+	if b.order == nil {
+		return binary.BigEndian
+	}
+	return b.order
+}
+
+// SetByteOrder sets the byte order of multi-byte values.
+func (b *GXByteBuffer) SetByteOrder(value binary.ByteOrder) {
+	b.order = value
+}
+
+// Size returns the number of bytes in the buffer.
+func (b *GXByteBuffer) Size() int {
+	return len(b.data)
+}
+
+// SetSize sets the number of bytes in the buffer. New bytes are zero. The
+// position is moved to the end if it is after it.
+//
+// It returns ErrArgumentOutOfRange if value is negative.
+func (b *GXByteBuffer) SetSize(value int) error {
+	if value < 0 {
+		return ErrArgumentOutOfRangeError("size")
+	}
+	if value > len(b.data) {
+		b.grow(value - len(b.data))
+	} else {
+		b.data = b.data[:value]
+	}
+	b.position = min(b.position, value)
+	return nil
+}
+
+// Capacity returns the number of bytes the buffer can hold without
+// allocating.
+func (b *GXByteBuffer) Capacity() int {
+	return cap(b.data)
+}
+
+// SetCapacity sets the capacity of the buffer. If value is less than Size,
+// the buffer is truncated.
+//
+// It returns ErrArgumentOutOfRange if value is negative.
+func (b *GXByteBuffer) SetCapacity(value int) error {
+	if value < 0 {
+		return ErrArgumentOutOfRangeError("capacity")
+	}
+	data := make([]byte, min(len(b.data), value), value)
+	copy(data, b.data)
+	b.data = data
+	b.position = min(b.position, len(data))
+	return nil
+}
+
+// Position returns the read position.
+func (b *GXByteBuffer) Position() int {
+	return b.position
+}
+
+// SetPosition sets the read position.
+//
+// It returns ErrArgumentOutOfRange if value is negative or after the end of
+// the data.
+func (b *GXByteBuffer) SetPosition(value int) error {
+	if value < 0 || value > len(b.data) {
+		return ErrArgumentOutOfRangeError("position")
+	}
+	b.position = value
+	return nil
+}
+
+// Available returns the number of bytes after the position.
+func (b *GXByteBuffer) Available() int {
+	return len(b.data) - b.position
+}
+
+// Data returns the bytes of the buffer. The slice is valid until the buffer
+// is modified.
+func (b *GXByteBuffer) Data() []byte {
+	return b.data
+}
+
+// Remaining returns the bytes after the position. The slice is valid until
+// the buffer is modified.
+func (b *GXByteBuffer) Remaining() []byte {
+	return b.data[b.position:]
+}
+
+// Clear removes all bytes and resets the position. The capacity is kept.
+func (b *GXByteBuffer) Clear() {
+	b.data = b.data[:0]
+	b.position = 0
+}
+
+// Trim removes the bytes before the position and resets the position.
+func (b *GXByteBuffer) Trim() {
+	b.data = b.data[:copy(b.data, b.data[b.position:])]
+	b.position = 0
+}
+
+// SubBuffer returns a view of count bytes starting at index. The view
+// shares the bytes with b, so changes to existing bytes are visible in
+// both, but appending to the view never overwrites b.
+//
+// It returns ErrArgumentOutOfRange if the range is outside of the data.
+func (b *GXByteBuffer) SubBuffer(index int, count int) (*GXByteBuffer, error) {
+	if index < 0 || count < 0 || index+count > len(b.data) {
+		return nil, ErrArgumentOutOfRangeError("index")
+	}
+	return &GXByteBuffer{data: b.data[index : index+count : index+count], order: b.order}, nil
+}
+
+// Compare reports whether the bytes after the position start with value.
+// If they do, the position is moved after them.
+func (b *GXByteBuffer) Compare(value []byte) bool {
+	if !bytes.HasPrefix(b.data[b.position:], value) {
+		return false
+	}
+	b.position += len(value)
+	return true
+}
+
+// Peek returns the next count bytes without moving the position. The slice
+// is valid until the buffer is modified.
+func (b *GXByteBuffer) Peek(count int) ([]byte, error) {
+	if count < 0 || b.Available() < count {
+		return nil, ErrBufferTooSmallError("Peek")
+	}
+	return b.data[b.position : b.position+count], nil
+}
+
+// String returns the bytes of the buffer as hex.
+// It satisfies fmt.Stringer.
+func (b *GXByteBuffer) String() string {
+	return ToHex(b.data)
+}
+
+// SetHex appends the bytes of a hex string. White space between the bytes
+// is ignored.
+//
+// It returns ErrInvalidArgument if value is not valid hex.
+func (b *GXByteBuffer) SetHex(value string) error {
+	value = strings.Join(strings.Fields(value), "")
+	data, err := hex.DecodeString(value)
+	if err != nil {
+		return ErrInvalidArgumentError("hex")
+	}
+	b.data = append(b.data, data...)
+	return nil
+}
+
+// grow appends count zero bytes and returns them.
+func (b *GXByteBuffer) grow(count int) []byte {
+	b.data = append(b.data, make([]byte, count)...)
+	return b.data[len(b.data)-count:]
+}
+
+// at returns count bytes starting at index. The buffer is extended with
+// zero bytes if the range passes its end.
+func (b *GXByteBuffer) at(index int, count int, name string) ([]byte, error) {
+	if index < 0 || index > len(b.data) {
+		return nil, ErrArgumentOutOfRangeError(name)
+	}
+	if n := index + count - len(b.data); n > 0 {
+		b.grow(n)
+	}
+	return b.data[index : index+count], nil
+}
+
+// next returns the next count bytes and moves the position after them.
+func (b *GXByteBuffer) next(count int, name string) ([]byte, error) {
+	if count < 0 || b.Available() < count {
+		return nil, ErrBufferTooSmallError(name)
+	}
+	ret := b.data[b.position : b.position+count]
+	b.position += count
+	return ret, nil
+}
+
+// SetUint8 appends a byte.
+func (b *GXByteBuffer) SetUint8(value uint8) {
+	b.data = append(b.data, value)
+}
+
+// SetUint8At writes a byte at index.
+//
+// It returns ErrArgumentOutOfRange if index is negative or after the end of
+// the data.
+func (b *GXByteBuffer) SetUint8At(index int, value uint8) error {
+	d, err := b.at(index, 1, "index")
+	if err != nil {
+		return err
+	}
+	d[0] = value
+	return nil
+}
+
+// GetUint8 reads a byte.
+func (b *GXByteBuffer) GetUint8() (uint8, error) {
+	d, err := b.next(1, "GetUint8")
+	if err != nil {
+		return 0, err
+	}
+	return d[0], nil
+}
+
+// SetInt8 appends an int8.
+func (b *GXByteBuffer) SetInt8(value int8) {
+	b.data = append(b.data, byte(value))
+}
+
+// GetInt8 reads an int8.
+func (b *GXByteBuffer) GetInt8() (int8, error) {
+	d, err := b.next(1, "GetInt8")
+	if err != nil {
+		return 0, err
+	}
+	return int8(d[0]), nil
+}
+
+// SetBool appends a bool as one byte.
+func (b *GXByteBuffer) SetBool(value bool) {
+	var v byte
+	if value {
+		v = 1
+	}
+	b.data = append(b.data, v)
+}
+
+// GetBool reads a bool. Any non-zero byte is true.
+func (b *GXByteBuffer) GetBool() (bool, error) {
+	d, err := b.next(1, "GetBool")
+	if err != nil {
+		return false, err
+	}
+	return d[0] != 0, nil
+}
+
+// SetUint16 appends a uint16.
+func (b *GXByteBuffer) SetUint16(value uint16) {
+	b.ByteOrder().PutUint16(b.grow(2), value)
+}
+
+// SetUint16At writes a uint16 at index. Bytes after the end of the
+// data are appended.
+//
+// It returns ErrArgumentOutOfRange if index is negative or after the end of
+// the data.
+func (b *GXByteBuffer) SetUint16At(index int, value uint16) error {
+	d, err := b.at(index, 2, "index")
+	if err != nil {
+		return err
+	}
+	b.ByteOrder().PutUint16(d, value)
+	return nil
+}
+
+// GetUint16 reads a uint16.
+func (b *GXByteBuffer) GetUint16() (uint16, error) {
+	d, err := b.next(2, "GetUint16")
+	if err != nil {
+		return 0, err
+	}
+	return b.ByteOrder().Uint16(d), nil
+}
+
+// SetInt16 appends an int16.
+func (b *GXByteBuffer) SetInt16(value int16) {
+	b.SetUint16(uint16(value))
+}
+
+// GetInt16 reads an int16.
+func (b *GXByteBuffer) GetInt16() (int16, error) {
+	d, err := b.next(2, "GetInt16")
+	if err != nil {
+		return 0, err
+	}
+	return int16(b.ByteOrder().Uint16(d)), nil
+}
+
+// SetUint32 appends a uint32.
+func (b *GXByteBuffer) SetUint32(value uint32) {
+	b.ByteOrder().PutUint32(b.grow(4), value)
+}
+
+// SetUint32At writes a uint32 at index. Bytes after the end of the
+// data are appended.
+//
+// It returns ErrArgumentOutOfRange if index is negative or after the end of
+// the data.
+func (b *GXByteBuffer) SetUint32At(index int, value uint32) error {
+	d, err := b.at(index, 4, "index")
+	if err != nil {
+		return err
+	}
+	b.ByteOrder().PutUint32(d, value)
+	return nil
+}
+
+// GetUint32 reads a uint32.
+func (b *GXByteBuffer) GetUint32() (uint32, error) {
+	d, err := b.next(4, "GetUint32")
+	if err != nil {
+		return 0, err
+	}
+	return b.ByteOrder().Uint32(d), nil
+}
+
+// SetInt32 appends an int32.
+func (b *GXByteBuffer) SetInt32(value int32) {
+	b.SetUint32(uint32(value))
+}
+
+// GetInt32 reads an int32.
+func (b *GXByteBuffer) GetInt32() (int32, error) {
+	d, err := b.next(4, "GetInt32")
+	if err != nil {
+		return 0, err
+	}
+	return int32(b.ByteOrder().Uint32(d)), nil
+}
+
+// SetUint64 appends a uint64.
+func (b *GXByteBuffer) SetUint64(value uint64) {
+	b.ByteOrder().PutUint64(b.grow(8), value)
+}
+
+// SetUint64At writes a uint64 at index. Bytes after the end of the
+// data are appended.
+//
+// It returns ErrArgumentOutOfRange if index is negative or after the end of
+// the data.
+func (b *GXByteBuffer) SetUint64At(index int, value uint64) error {
+	d, err := b.at(index, 8, "index")
+	if err != nil {
+		return err
+	}
+	b.ByteOrder().PutUint64(d, value)
+	return nil
+}
+
+// GetUint64 reads a uint64.
+func (b *GXByteBuffer) GetUint64() (uint64, error) {
+	d, err := b.next(8, "GetUint64")
+	if err != nil {
+		return 0, err
+	}
+	return b.ByteOrder().Uint64(d), nil
+}
+
+// SetInt64 appends an int64.
+func (b *GXByteBuffer) SetInt64(value int64) {
+	b.SetUint64(uint64(value))
+}
+
+// GetInt64 reads an int64.
+func (b *GXByteBuffer) GetInt64() (int64, error) {
+	d, err := b.next(8, "GetInt64")
+	if err != nil {
+		return 0, err
+	}
+	return int64(b.ByteOrder().Uint64(d)), nil
+}
+
+// SetInt appends an int as 8 bytes.
+func (b *GXByteBuffer) SetInt(value int) {
+	b.SetUint64(uint64(value))
+}
+
+// GetInt reads an int from 8 bytes.
+//
+// It returns ErrArgumentOutOfRange if the value does not fit to int.
+func (b *GXByteBuffer) GetInt() (int, error) {
+	d, err := b.Peek(8)
+	if err != nil {
+		return 0, ErrBufferTooSmallError("GetInt")
+	}
+	v := int64(b.ByteOrder().Uint64(d))
+	if int64(int(v)) != v {
+		return 0, ErrArgumentOutOfRangeError("int")
+	}
+	b.position += 8
+	return int(v), nil
+}
+
+// SetUint appends a uint as 8 bytes.
+func (b *GXByteBuffer) SetUint(value uint) {
+	b.SetUint64(uint64(value))
+}
+
+// GetUint reads a uint from 8 bytes.
+//
+// It returns ErrArgumentOutOfRange if the value does not fit to uint.
+func (b *GXByteBuffer) GetUint() (uint, error) {
+	d, err := b.Peek(8)
+	if err != nil {
+		return 0, ErrBufferTooSmallError("GetUint")
+	}
+	v := b.ByteOrder().Uint64(d)
+	if uint64(uint(v)) != v {
+		return 0, ErrArgumentOutOfRangeError("uint")
+	}
+	b.position += 8
+	return uint(v), nil
+}
+
+// SetFloat32 appends a float32 in IEEE 754 format.
+func (b *GXByteBuffer) SetFloat32(value float32) {
+	b.SetUint32(math.Float32bits(value))
+}
+
+// GetFloat32 reads a float32 in IEEE 754 format.
+func (b *GXByteBuffer) GetFloat32() (float32, error) {
+	d, err := b.next(4, "GetFloat32")
+	if err != nil {
+		return 0, err
+	}
+	return math.Float32frombits(b.ByteOrder().Uint32(d)), nil
+}
+
+// SetFloat64 appends a float64 in IEEE 754 format.
+func (b *GXByteBuffer) SetFloat64(value float64) {
+	b.SetUint64(math.Float64bits(value))
+}
+
+// GetFloat64 reads a float64 in IEEE 754 format.
+func (b *GXByteBuffer) GetFloat64() (float64, error) {
+	d, err := b.next(8, "GetFloat64")
+	if err != nil {
+		return 0, err
+	}
+	return math.Float64frombits(b.ByteOrder().Uint64(d)), nil
+}
+
+// SetRune appends a rune as UTF-8.
+func (b *GXByteBuffer) SetRune(value rune) {
+	b.data = utf8.AppendRune(b.data, value)
+}
+
+// GetRune reads a UTF-8 encoded rune.
+//
+// It returns ErrInvalidArgument if the data is not valid UTF-8.
+func (b *GXByteBuffer) GetRune() (rune, error) {
+	if b.Available() == 0 || !utf8.FullRune(b.data[b.position:]) {
+		return 0, ErrBufferTooSmallError("GetRune")
+	}
+	r, size := utf8.DecodeRune(b.data[b.position:])
+	if r == utf8.RuneError && size <= 1 {
+		return 0, ErrInvalidArgumentError("rune")
+	}
+	b.position += size
+	return r, nil
+}
+
+// SetBytes appends bytes.
+func (b *GXByteBuffer) SetBytes(value []byte) {
+	b.data = append(b.data, value...)
+}
+
+// SetBytesAt writes bytes at index. Bytes after the end of the data are
+// appended.
+//
+// It returns ErrArgumentOutOfRange if index is negative or after the end of
+// the data.
+func (b *GXByteBuffer) SetBytesAt(index int, value []byte) error {
+	d, err := b.at(index, len(value), "index")
+	if err != nil {
+		return err
+	}
+	copy(d, value)
+	return nil
+}
+
+// GetBytes reads count bytes to a new slice.
+func (b *GXByteBuffer) GetBytes(count int) ([]byte, error) {
+	d, err := b.next(count, "GetBytes")
+	if err != nil {
+		return nil, err
+	}
+	return bytes.Clone(d), nil
+}
+
+// SetString appends the bytes of a string.
+func (b *GXByteBuffer) SetString(value string) {
+	b.data = append(b.data, value...)
+}
+
+// GetString reads count bytes as a string.
+func (b *GXByteBuffer) GetString(count int) (string, error) {
+	d, err := b.next(count, "GetString")
+	if err != nil {
+		return "", err
+	}
+	return string(d), nil
+}
+
+// Set appends a value of any type supported by ToBytes in the byte order of
+// the buffer.
+func (b *GXByteBuffer) Set(value any) error {
+	data, err := AppendBytes(b.data, value, b.ByteOrder())
+	if err != nil {
+		// Arrays are only supported by ToBytes.
+		if data, err = ToBytes(value, b.ByteOrder()); err != nil {
+			return err
+		}
+		data = append(b.data, data...)
+	}
+	b.data = data
+	return nil
+}
+
+// SetAt writes a value of any type supported by ToBytes at index in the
+// byte order of the buffer. Bytes after the end of the data are appended.
+//
+// It returns ErrArgumentOutOfRange if index is negative or after the end of
+// the data.
+func (b *GXByteBuffer) SetAt(index int, value any) error {
+	if index < 0 || index > len(b.data) {
+		return ErrArgumentOutOfRangeError("index")
+	}
+	data, err := ToBytes(value, b.ByteOrder())
+	if err != nil {
+		return err
+	}
+	return b.SetBytesAt(index, data)
+}
+
+// Get reads a value of the given DataType with BytesToAny2. count is the
+// number of bytes of strings, byte slices and the BCD types and the number
+// of elements of slice types. It is ignored for other types.
+//
+// It returns ErrInvalidArgument if t is DataTypeUnknown.
+func (b *GXByteBuffer) Get(t DataType, count int) (any, error) {
+	if t == DataTypeRune {
+		return b.GetRune()
+	}
+	var size int
+	switch {
+	case t == DataTypeString, t == DataTypeBytes, t == DataTypeBCD,
+		t == DataTypePackedDecimal:
+		size = count
+	case t.isSlice():
+		size = count * t.ElementSize()
+	default:
+		size = t.ElementSize()
+	}
+	if size == 0 && t.ElementSize() == 0 {
+		return nil, ErrInvalidArgumentError(t.String())
+	}
+	d, err := b.Peek(size)
+	if err != nil {
+		return nil, ErrBufferTooSmallError("Get")
+	}
+	ret, err := BytesToAny2(d, t, b.ByteOrder())
+	if err != nil {
+		return nil, err
+	}
+	b.position += size
+	return ret, nil
+}
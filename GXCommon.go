@@ -1,12 +1,14 @@
 package gxcommon
 
 import (
-	"bytes"
 	"encoding/binary"
+	"errors"
 	"fmt"
 	"io"
+	"reflect"
 	"sync"
 	"sync/atomic"
+	"unicode/utf8"
 
 	"golang.org/x/text/language"
 )
@@ -72,6 +74,45 @@ const (
 	DataTypeUint32
 	// DataTypeUint64 represents uint64.
 	DataTypeUint64
+	// DataTypeFloat32 represents float32 in IEEE 754 format.
+	DataTypeFloat32
+	// DataTypeFloat64 represents float64 in IEEE 754 format.
+	DataTypeFloat64
+	// DataTypeBool represents bool as one byte.
+	DataTypeBool
+	// DataTypeRune represents a rune encoded as UTF-8.
+	//
+	// rune is an alias of int32, so GetType[rune] returns DataTypeInt32 and
+	// DataTypeRune must be selected explicitly.
+	DataTypeRune
+	// DataTypeInt represents int as 8 bytes.
+	DataTypeInt
+	// DataTypeUint represents uint as 8 bytes.
+	DataTypeUint
+	// DataTypeInt8Slice represents []int8.
+	DataTypeInt8Slice
+	// DataTypeInt16Slice represents []int16.
+	DataTypeInt16Slice
+	// DataTypeInt32Slice represents []int32.
+	DataTypeInt32Slice
+	// DataTypeInt64Slice represents []int64.
+	DataTypeInt64Slice
+	// DataTypeUint16Slice represents []uint16.
+	DataTypeUint16Slice
+	// DataTypeUint32Slice represents []uint32.
+	DataTypeUint32Slice
+	// DataTypeUint64Slice represents []uint64.
+	DataTypeUint64Slice
+	// DataTypeFloat32Slice represents []float32.
+	DataTypeFloat32Slice
+	// DataTypeFloat64Slice represents []float64.
+	DataTypeFloat64Slice
+	// DataTypeBCD represents uint64 encoded as packed BCD, two digits in a
+	// byte. See AppendBCD.
+	DataTypeBCD
+	// DataTypePackedDecimal represents int64 encoded as a signed packed
+	// decimal with a trailing sign nibble. See AppendPackedDecimal.
+	DataTypePackedDecimal
 )
 
 var currentLang atomic.Value
@@ -106,6 +147,40 @@ func (dt DataType) String() string {
 		return "Uint32"
 	case DataTypeUint64:
 		return "Uint64"
+	case DataTypeFloat32:
+		return "Float32"
+	case DataTypeFloat64:
+		return "Float64"
+	case DataTypeBool:
+		return "Bool"
+	case DataTypeRune:
+		return "Rune"
+	case DataTypeInt:
+		return "Int"
+	case DataTypeUint:
+		return "Uint"
+	case DataTypeInt8Slice:
+		return "Int8Slice"
+	case DataTypeInt16Slice:
+		return "Int16Slice"
+	case DataTypeInt32Slice:
+		return "Int32Slice"
+	case DataTypeInt64Slice:
+		return "Int64Slice"
+	case DataTypeUint16Slice:
+		return "Uint16Slice"
+	case DataTypeUint32Slice:
+		return "Uint32Slice"
+	case DataTypeUint64Slice:
+		return "Uint64Slice"
+	case DataTypeFloat32Slice:
+		return "Float32Slice"
+	case DataTypeFloat64Slice:
+		return "Float64Slice"
+	case DataTypeBCD:
+		return "BCD"
+	case DataTypePackedDecimal:
+		return "PackedDecimal"
 	default:
 		return "Unknown"
 	}
@@ -126,19 +201,71 @@ func AllDataTypes() []DataType {
 		DataTypeUint16,
 		DataTypeUint32,
 		DataTypeUint64,
+		DataTypeFloat32,
+		DataTypeFloat64,
+		DataTypeBool,
+		DataTypeRune,
+		DataTypeInt,
+		DataTypeUint,
+		DataTypeInt8Slice,
+		DataTypeInt16Slice,
+		DataTypeInt32Slice,
+		DataTypeInt64Slice,
+		DataTypeUint16Slice,
+		DataTypeUint32Slice,
+		DataTypeUint64Slice,
+		DataTypeFloat32Slice,
+		DataTypeFloat64Slice,
+		DataTypeBCD,
+		DataTypePackedDecimal,
 	}
 }
 
+// ElementSize returns the size of a value of the type in bytes. For slice
+// types, strings and byte slices it returns the size of one element. It
+// returns zero if the size is variable, as with DataTypeRune and the BCD
+// types, or if the type is unknown.
+func (dt DataType) ElementSize() int {
+	switch dt {
+	case DataTypeString, DataTypeBytes, DataTypeUint8, DataTypeInt8, DataTypeBool,
+		DataTypeInt8Slice:
+		return 1
+	case DataTypeInt16, DataTypeUint16, DataTypeInt16Slice, DataTypeUint16Slice:
+		return 2
+	case DataTypeInt32, DataTypeUint32, DataTypeFloat32, DataTypeInt32Slice,
+		DataTypeUint32Slice, DataTypeFloat32Slice:
+		return 4
+	case DataTypeInt64, DataTypeUint64, DataTypeFloat64, DataTypeInt, DataTypeUint,
+		DataTypeInt64Slice, DataTypeUint64Slice, DataTypeFloat64Slice:
+		return 8
+	default:
+		return 0
+	}
+}
+
+// isSlice reports whether dt is a slice of numbers.
+func (dt DataType) isSlice() bool {
+	return dt >= DataTypeInt8Slice && dt <= DataTypeFloat64Slice
+}
+
 // GetType returns the DataType that corresponds to T.
 func GetType[T any]() DataType {
 	var zero T
-	switch any(zero).(type) {
+	return typeOf(zero)
+}
+
+// typeOf returns the DataType of value or DataTypeUnknown if the type is not
+// supported.
+func typeOf(value any) DataType {
+	switch value.(type) {
 	case string:
 		return DataTypeString
 	case []byte:
 		return DataTypeBytes
 	case uint8:
 		return DataTypeUint8
+	case int8:
+		return DataTypeInt8
 	case int16:
 		return DataTypeInt16
 	case int32:
@@ -151,6 +278,34 @@ func GetType[T any]() DataType {
 		return DataTypeUint32
 	case uint64:
 		return DataTypeUint64
+	case float32:
+		return DataTypeFloat32
+	case float64:
+		return DataTypeFloat64
+	case bool:
+		return DataTypeBool
+	case int:
+		return DataTypeInt
+	case uint:
+		return DataTypeUint
+	case []int8:
+		return DataTypeInt8Slice
+	case []int16:
+		return DataTypeInt16Slice
+	case []int32:
+		return DataTypeInt32Slice
+	case []int64:
+		return DataTypeInt64Slice
+	case []uint16:
+		return DataTypeUint16Slice
+	case []uint32:
+		return DataTypeUint32Slice
+	case []uint64:
+		return DataTypeUint64Slice
+	case []float32:
+		return DataTypeFloat32Slice
+	case []float64:
+		return DataTypeFloat64Slice
 	default:
 		return DataTypeUnknown
 	}
@@ -171,6 +326,10 @@ func ToString(value any) (string, error) {
 }
 
 // BytesToAny2 converts b to a value of the given DataType.
+//
+// It is the counterpart of ToBytes2. DataTypeRune decodes the first UTF-8
+// encoded rune of b. DataTypeBCD and DataTypePackedDecimal decode all of b
+// and return ErrInvalidArgument if a nibble is not valid.
 func BytesToAny2(b []byte, t DataType, order binary.ByteOrder) (any, error) {
 	switch t {
 	case DataTypeString:
@@ -193,129 +352,137 @@ func BytesToAny2(b []byte, t DataType, order binary.ByteOrder) (any, error) {
 		return BytesToAny[uint32](b, order)
 	case DataTypeUint64:
 		return BytesToAny[uint64](b, order)
+	case DataTypeFloat32:
+		return BytesToAny[float32](b, order)
+	case DataTypeFloat64:
+		return BytesToAny[float64](b, order)
+	case DataTypeBool:
+		return BytesToAny[bool](b, order)
+	case DataTypeRune:
+		r, size := utf8.DecodeRune(b)
+		if r == utf8.RuneError && size <= 1 {
+			if len(b) == 0 {
+				return nil, ErrBufferTooSmallError("rune")
+			}
+			return nil, ErrInvalidArgumentError("rune")
+		}
+		return r, nil
+	case DataTypeInt:
+		return BytesToAny[int](b, order)
+	case DataTypeUint:
+		return BytesToAny[uint](b, order)
+	case DataTypeInt8Slice:
+		return BytesToAny[[]int8](b, order)
+	case DataTypeInt16Slice:
+		return BytesToAny[[]int16](b, order)
+	case DataTypeInt32Slice:
+		return BytesToAny[[]int32](b, order)
+	case DataTypeInt64Slice:
+		return BytesToAny[[]int64](b, order)
+	case DataTypeUint16Slice:
+		return BytesToAny[[]uint16](b, order)
+	case DataTypeUint32Slice:
+		return BytesToAny[[]uint32](b, order)
+	case DataTypeUint64Slice:
+		return BytesToAny[[]uint64](b, order)
+	case DataTypeFloat32Slice:
+		return BytesToAny[[]float32](b, order)
+	case DataTypeFloat64Slice:
+		return BytesToAny[[]float64](b, order)
+	case DataTypeBCD:
+		return ParseBCD(b)
+	case DataTypePackedDecimal:
+		return ParsePackedDecimal(b)
 	default:
 		return nil, ErrInvalidArgument
 	}
 }
 
 // BytesToAny converts b to type T using the given byte order.
+//
+// It is the counterpart of ToBytes and accepts the same types. int and uint
+// are read from 8 bytes and bool is true if the byte is not zero. Slices are
+// decoded from all of b, which must be a multiple of the element size.
+// Arrays of fixed-size values, such as [16]uint16, are decoded from the
+// beginning of b.
 func BytesToAny[T any](b []byte, order binary.ByteOrder) (T, error) {
-	var zero T
-
-	switch any(zero).(type) {
-	case string:
-		// koko puskuri UTF-8:na
-		return any(string(b)).(T), nil
-
-	case []byte:
-		cp := make([]byte, len(b))
-		copy(cp, b)
-		return any(cp).(T), nil
-
-	case uint8:
-		if len(b) < 1 {
-			return zero, fmt.Errorf("buffer too short for uint8")
-		}
-		return any(b[0]).(T), nil
-	case int16:
-		var v int16
-		if err := readFixed(b, order, &v); err != nil {
-			return zero, err
-		}
-		return any(v).(T), nil
-	case int32:
-		var v int32
-		if err := readFixed(b, order, &v); err != nil {
-			return zero, err
-		}
-		return any(v).(T), nil
-	case int64:
-		var v int64
-		if err := readFixed(b, order, &v); err != nil {
-			return zero, err
-		}
-		return any(v).(T), nil
-
-	case uint16:
-		var v uint16
-		if err := readFixed(b, order, &v); err != nil {
-			return zero, err
-		}
-		return any(v).(T), nil
-	case uint32:
-		var v uint32
-		if err := readFixed(b, order, &v); err != nil {
-			return zero, err
+	var ret T
+	_, err := DecodeInto(b, &ret, order)
+	if err != nil && errors.Is(err, ErrInvalidArgument) && reflect.TypeFor[T]().Kind() == reflect.Array {
+		// Arrays are decoded with reflection.
+		_, err = binary.Decode(b, order, &ret)
+		if errors.Is(err, io.ErrUnexpectedEOF) {
+			err = errShort(reflect.TypeFor[T]().String())
 		}
-		return any(v).(T), nil
-	case uint64:
-		var v uint64
-		if err := readFixed(b, order, &v); err != nil {
-			return zero, err
-		}
-		return any(v).(T), nil
-	}
-
-	return zero, fmt.Errorf("unsupported target type %T", zero)
-}
-
-// readFixed is used to read value to the output.
-func readFixed(b []byte, order binary.ByteOrder, out any) error {
-	var need int
-	switch out.(type) {
-	case *int16, *uint16:
-		need = 2
-	case *int32, *uint32:
-		need = 4
-	case *int64, *uint64:
-		need = 8
-	default:
-		return fmt.Errorf("unsupported fixed-size type %T", out)
 	}
-	if len(b) < need {
-		return io.ErrUnexpectedEOF
+	if err != nil {
+		var zero T
+		return zero, err
 	}
-	return binary.Read(bytes.NewReader(b[:need]), order, out)
+	return ret, nil
 }
 
 // ToBytes converts a supported value to bytes using the given byte order.
+//
+// Supported types are string, []byte, bool, int8, int16, int32, int64, int,
+// uint8, uint16, uint32, uint64, uint, float32 and float64, slices of the
+// fixed-size types except bool, and arrays of fixed-size values. int and uint
+// are written as 8 bytes and bool as one byte. Use ToBytes2 to write a rune
+// as UTF-8.
 func ToBytes(v any, order binary.ByteOrder) ([]byte, error) {
-	if v == nil {
-		return []byte{}, nil
-	}
 	switch x := v.(type) {
+	case nil:
+		return []byte{}, nil
 	case []byte:
 		return x, nil
-	case string:
-		return []byte(x), nil
-	case uint8:
-		return []byte{any(x).(byte)}, nil
-	case int16:
-		return writeFixed(order, x)
-	case int32:
-		return writeFixed(order, x)
-	case int64:
-		return writeFixed(order, x)
-	case int:
-		return writeFixed(order, int64(x))
-	case uint16:
-		return writeFixed(order, x)
-	case uint32:
-		return writeFixed(order, x)
-	case uint64:
-		return writeFixed(order, x)
-	case uint:
-		return writeFixed(order, uint64(x))
 	}
-	return nil, fmt.Errorf("ToBytes: unsupported type %T", v)
+	if reflect.TypeOf(v).Kind() == reflect.Array {
+		// Arrays are encoded with reflection.
+		ret, err := binary.Append(nil, order, v)
+		if err != nil {
+			return nil, ErrInvalidArgumentError("unsupported type " + reflect.TypeOf(v).String())
+		}
+		return ret, nil
+	}
+	return AppendBytes(nil, v, order)
 }
 
-func writeFixed[T any](order binary.ByteOrder, x T) ([]byte, error) {
-	var buf bytes.Buffer
-	if err := binary.Write(&buf, order, x); err != nil {
-		return nil, err
+// ToBytes2 converts v to bytes as the given DataType.
+//
+// It is the counterpart of BytesToAny2. DataTypeRune writes v as UTF-8,
+// DataTypeBCD writes a uint64 and DataTypePackedDecimal an int64 with as few
+// bytes as possible, and the other types are written with ToBytes. It
+// returns ErrInvalidArgument if v is not of type t or if it is not a valid
+// rune.
+func ToBytes2(v any, t DataType, order binary.ByteOrder) ([]byte, error) {
+	switch t {
+	case DataTypeRune:
+		r, ok := v.(rune)
+		if !ok || !utf8.ValidRune(r) {
+			return nil, ErrInvalidArgumentError("rune")
+		}
+		return utf8.AppendRune(nil, r), nil
+	case DataTypeBCD:
+		x, ok := v.(uint64)
+		if !ok {
+			return nil, ErrInvalidArgumentError(t.String())
+		}
+		return AppendBCD(nil, x, bcdDigits(x))
+	case DataTypePackedDecimal:
+		x, ok := v.(int64)
+		if !ok {
+			return nil, ErrInvalidArgumentError(t.String())
+		}
+		magnitude := uint64(x)
+		if x < 0 {
+			magnitude = -magnitude
+		}
+		return AppendPackedDecimal(nil, x, bcdDigits(magnitude))
+	}
+	if t == DataTypeUnknown || typeOf(v) != t {
+		return nil, ErrInvalidArgumentError(t.String())
 	}
-	return buf.Bytes(), nil
+	return ToBytes(v, order)
 }
 
 // SetLanguage sets the package-wide language used by internal status and error
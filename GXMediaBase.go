@@ -0,0 +1,321 @@
+package gxcommon
+
+// --------------------------------------------------------------------------
+//
+//	Gurux Ltd
+//
+// Filename:        $HeadURL$
+//
+// Version:         $Revision$,
+//
+//	$Date$
+//	$Author$
+//
+// # Copyright (c) Gurux Ltd
+//
+// ---------------------------------------------------------------------------
+//
+//	DESCRIPTION
+//
+// This file is a part of Gurux Device Framework.
+//
+// Gurux Device Framework is Open Source software; you can redistribute it
+// and/or modify it under the terms of the GNU General Public License
+// as published by the Free Software Foundation; version 2 of the License.
+// Gurux Device Framework is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.
+// See the GNU General Public License for more details.
+//
+// More information of Gurux products: https://www.gurux.org
+//
+// This code is licensed under the GNU General Public License v2.
+// Full text may be retrieved at http://www.gnu.org/licenses/gpl-2.0.txt
+// ---------------------------------------------------------------------------
+
+import (
+	"bytes"
+	"context"
+	"encoding/binary"
+	"sync"
+	"sync/atomic"
+)
+
+// GXMediaBase implements the media independent part of IGXMedia.
+//
+// GXMediaBase is meant to be embedded in a concrete media. It provides the
+// event handler slots, byte counters, trace level, end-of-packet marker,
+// synchronous mode and the Receive buffering logic. The concrete media only
+// implements opening, closing and writing and reports what happens on the
+// connection with the Notify methods:
+//
+//   - NotifyMediaStateChange when the connection state changes,
+//   - NotifySent after data has been written,
+//   - NotifyReceived when data arrives from the connection,
+//   - NotifyError when an error occurs.
+//
+// The media argument of the Notify methods is the concrete media and it is
+// passed to the event handlers as the event source.
+//
+// The zero value is ready to use. GXMediaBase is safe for concurrent use and
+// must not be copied after first use.
+type GXMediaBase struct {
+	mu                 sync.RWMutex
+	onReceived         ReceivedEventHandler
+	onError            ErrorEventHandler
+	onMediaStateChange MediaStateHandler
+	onTrace            TraceEventHandler
+	eop                any
+	// received holds asynchronously received data until EOP is found.
+	received      []byte
+	trace         atomic.Int32
+	synchronous   atomic.Int32
+	bytesSent     atomic.Uint64
+	bytesReceived atomic.Uint64
+	buffer        SyncBuffer
+}
+
+// SetOnReceived sets a callback for asynchronously received data.
+func (b *GXMediaBase) SetOnReceived(value ReceivedEventHandler) {
+	b.mu.Lock()
+	b.onReceived = value
+	b.mu.Unlock()
+}
+
+// SetOnError sets a callback for media errors.
+func (b *GXMediaBase) SetOnError(value ErrorEventHandler) {
+	b.mu.Lock()
+	b.onError = value
+	b.mu.Unlock()
+}
+
+// SetOnMediaStateChange sets a callback for media state changes.
+func (b *GXMediaBase) SetOnMediaStateChange(value MediaStateHandler) {
+	b.mu.Lock()
+	b.onMediaStateChange = value
+	b.mu.Unlock()
+}
+
+// SetOnTrace sets a callback for trace events.
+func (b *GXMediaBase) SetOnTrace(value TraceEventHandler) {
+	b.mu.Lock()
+	b.onTrace = value
+	b.mu.Unlock()
+}
+
+// GetTrace returns the current trace level.
+func (b *GXMediaBase) GetTrace() TraceLevel {
+	return TraceLevel(b.trace.Load())
+}
+
+// SetTrace sets the trace level.
+//
+// It returns ErrArgumentOutOfRange if value is not a defined TraceLevel.
+func (b *GXMediaBase) SetTrace(value TraceLevel) error {
+	if value < TraceLevelOff || value > TraceLevelVerbose {
+		return ErrArgumentOutOfRangeError("TraceLevel")
+	}
+	b.trace.Store(int32(value))
+	return nil
+}
+
+// SetEop sets the end-of-packet marker.
+//
+// When EOP is set, asynchronously received data is buffered and the
+// received event is raised once for each packet that ends with EOP.
+func (b *GXMediaBase) SetEop(value any) {
+	b.mu.Lock()
+	b.eop = value
+	b.received = nil
+	b.mu.Unlock()
+}
+
+// GetEop returns the configured end-of-packet marker.
+func (b *GXMediaBase) GetEop() any {
+	b.mu.RLock()
+	defer b.mu.RUnlock()
+	return b.eop
+}
+
+// GetByteOrder returns the byte order used to convert sent data, EOP and
+// replies. The default is big-endian.
+func (b *GXMediaBase) GetByteOrder() binary.ByteOrder {
+	return b.buffer.ByteOrder()
+}
+
+// SetByteOrder sets the byte order used to convert sent data, EOP and
+// replies. It is the default for ReceiveParameters.ByteOrder. A nil value
+// restores big-endian.
+func (b *GXMediaBase) SetByteOrder(value binary.ByteOrder) {
+	b.buffer.SetByteOrder(value)
+}
+
+// GetSynchronous enters synchronous mode and returns a function that
+// restores the previous mode.
+//
+// Calls can be nested. The media stays in synchronous mode until every
+// returned function has been called.
+func (b *GXMediaBase) GetSynchronous() func() {
+	b.synchronous.Add(1)
+	var once sync.Once
+	return func() {
+		once.Do(func() {
+			b.synchronous.Add(-1)
+		})
+	}
+}
+
+// IsSynchronous reports whether synchronous mode is enabled.
+func (b *GXMediaBase) IsSynchronous() bool {
+	return b.synchronous.Load() != 0
+}
+
+// ResetSynchronousBuffer clears the synchronous receive buffer.
+func (b *GXMediaBase) ResetSynchronousBuffer() {
+	b.buffer.Clear()
+}
+
+// GetBytesSent returns the sent byte count.
+func (b *GXMediaBase) GetBytesSent() uint64 {
+	return b.bytesSent.Load()
+}
+
+// GetBytesReceived returns the received byte count.
+func (b *GXMediaBase) GetBytesReceived() uint64 {
+	return b.bytesReceived.Load()
+}
+
+// ResetByteCounters resets sent and received byte counters.
+func (b *GXMediaBase) ResetByteCounters() {
+	b.bytesSent.Store(0)
+	b.bytesReceived.Store(0)
+}
+
+// Receive waits for reply data according to args.
+//
+// See SyncBuffer for how args are interpreted. Data is available for
+// Receive only while the media is in synchronous mode. The returned bool
+// reports whether the requested data was received before WaitTime elapsed.
+// Receive returns ErrConnectionClosed after the media has been closed.
+func (b *GXMediaBase) Receive(args *ReceiveParameters) (bool, error) {
+	return b.buffer.Receive(args)
+}
+
+// ReceiveContext is like Receive but it stops waiting and returns ctx.Err()
+// when ctx is done.
+func (b *GXMediaBase) ReceiveContext(ctx context.Context, args *ReceiveParameters) (bool, error) {
+	return b.buffer.ReceiveContext(ctx, args)
+}
+
+// NotifyMediaStateChange raises the media state change event.
+func (b *GXMediaBase) NotifyMediaStateChange(media IGXMedia, state MediaState) {
+	switch state {
+	case MediaStateOpen:
+		b.buffer.Reset()
+		b.mu.Lock()
+		b.received = nil
+		b.mu.Unlock()
+	case MediaStateClosed:
+		b.buffer.Close()
+	}
+	b.NotifyTrace(media, TraceTypesInfo, state, "")
+	b.mu.RLock()
+	h := b.onMediaStateChange
+	b.mu.RUnlock()
+	if h != nil {
+		h(media, *NewMediaStateEventArgs(state))
+	}
+}
+
+// NotifySent updates the sent byte counter and traces the sent data.
+func (b *GXMediaBase) NotifySent(media IGXMedia, data []byte, receiver string) {
+	b.bytesSent.Add(uint64(len(data)))
+	b.NotifyTrace(media, TraceTypesSent, data, receiver)
+}
+
+// NotifyReceived handles data received from the connection.
+//
+// In synchronous mode data is added to the receive buffer and read with
+// Receive. Otherwise the received event is raised, once for each packet if
+// EOP is set.
+func (b *GXMediaBase) NotifyReceived(media IGXMedia, data []byte, sender string) {
+	if len(data) == 0 {
+		return
+	}
+	b.bytesReceived.Add(uint64(len(data)))
+	b.NotifyTrace(media, TraceTypesReceived, data, sender)
+	if b.IsSynchronous() {
+		b.buffer.Append(data)
+		return
+	}
+	b.mu.Lock()
+	h := b.onReceived
+	var eop []byte
+	var err error
+	if b.eop != nil {
+		eop, err = eopBytes(b.eop, b.buffer.ByteOrder())
+	}
+	var packets [][]byte
+	if len(eop) == 0 || err != nil {
+		packets = append(packets, bytes.Clone(data))
+	} else {
+		b.received = append(b.received, data...)
+		for {
+			pos := bytes.Index(b.received, eop)
+			if pos == -1 {
+				break
+			}
+			pos += len(eop)
+			packets = append(packets, bytes.Clone(b.received[:pos]))
+			b.received = b.received[pos:]
+		}
+	}
+	b.mu.Unlock()
+	if err != nil {
+		b.NotifyError(media, err)
+	}
+	if h != nil {
+		for _, it := range packets {
+			h(media, *NewReceiveEventArgs(it, sender))
+		}
+	}
+}
+
+// NotifyError traces err and raises the error event.
+func (b *GXMediaBase) NotifyError(media IGXMedia, err error) {
+	b.NotifyTrace(media, TraceTypesError, err, "")
+	b.mu.RLock()
+	h := b.onError
+	b.mu.RUnlock()
+	if h != nil {
+		h(media, err)
+	}
+}
+
+// NotifyTrace raises the trace event if the current trace level includes
+// traceType.
+func (b *GXMediaBase) NotifyTrace(media IGXMedia, traceType TraceTypes, data any, receiver string) {
+	if b.GetTrace() < traceLevelOf(traceType) {
+		return
+	}
+	b.mu.RLock()
+	h := b.onTrace
+	b.mu.RUnlock()
+	if h != nil {
+		h(media, *NewTraceEventArgs(traceType, data, receiver))
+	}
+}
+
+// traceLevelOf returns the trace level that is required to emit traceType.
+func traceLevelOf(traceType TraceTypes) TraceLevel {
+	switch traceType {
+	case TraceTypesError:
+		return TraceLevelError
+	case TraceTypesWarning:
+		return TraceLevelWarning
+	case TraceTypesInfo:
+		return TraceLevelInfo
+	default:
+		return TraceLevelVerbose
+	}
+}
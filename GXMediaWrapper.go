@@ -0,0 +1,198 @@
+package gxcommon
+
+// --------------------------------------------------------------------------
+//
+//	Gurux Ltd
+//
+// Filename:        $HeadURL$
+//
+// Version:         $Revision$,
+//
+//	$Date$
+//	$Author$
+//
+// # Copyright (c) Gurux Ltd
+//
+// ---------------------------------------------------------------------------
+//
+//	DESCRIPTION
+//
+// This file is a part of Gurux Device Framework.
+//
+// Gurux Device Framework is Open Source software; you can redistribute it
+// and/or modify it under the terms of the GNU General Public License
+// as published by the Free Software Foundation; version 2 of the License.
+// Gurux Device Framework is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.
+// See the GNU General Public License for more details.
+//
+// More information of Gurux products: https://www.gurux.org
+//
+// This code is licensed under the GNU General Public License v2.
+// Full text may be retrieved at http://www.gnu.org/licenses/gpl-2.0.txt
+// ---------------------------------------------------------------------------
+
+import "encoding/binary"
+
+// mediaNotifier is implemented by medias that embed GXMediaBase. Wrappers
+// override these methods to see what happens on the inner media.
+type mediaNotifier interface {
+	NotifyMediaStateChange(media IGXMedia, state MediaState)
+	NotifyReceived(media IGXMedia, data []byte, sender string)
+	NotifyError(media IGXMedia, err error)
+}
+
+// byteOrderer is implemented by medias that have a configurable byte order.
+type byteOrderer interface {
+	GetByteOrder() binary.ByteOrder
+	SetByteOrder(value binary.ByteOrder)
+}
+
+// GXMediaWrapper is an embeddable base for medias that add behavior to
+// another media, the inner media.
+//
+// Opening, closing, settings and validation are forwarded to the inner
+// media. Events of the inner media are delivered to the wrapping media by
+// calling its NotifyMediaStateChange, NotifyReceived and NotifyError methods,
+// so a wrapper can override them to observe or modify the events before it
+// calls the GXMediaWrapper versions. The inner media is used in
+// asynchronous mode and the wrapper keeps its own synchronous receive
+// buffer, counters and trace events. The byte order follows the byte order
+// of the inner media if the inner media has one.
+//
+// InitWrapper must be called before the wrapper is used.
+type GXMediaWrapper struct {
+	GXMediaBase
+	inner IGXMedia
+	outer IGXMedia
+}
+
+// InitWrapper connects the wrapper to inner. outer is the media that embeds
+// the wrapper and it is used as the event source.
+//
+// InitWrapper replaces the received, error and media state handlers of
+// inner.
+func (w *GXMediaWrapper) InitWrapper(outer IGXMedia, inner IGXMedia) {
+	w.inner = inner
+	w.outer = outer
+	w.syncByteOrder()
+	var n mediaNotifier = w
+	if it, ok := outer.(mediaNotifier); ok {
+		n = it
+	}
+	inner.SetOnReceived(func(_ IGXMedia, e ReceiveEventArgs) {
+		n.NotifyReceived(outer, e.Data(), e.SenderInfo())
+	})
+	inner.SetOnError(func(_ IGXMedia, err error) {
+		n.NotifyError(outer, err)
+	})
+	inner.SetOnMediaStateChange(func(_ IGXMedia, e MediaStateEventArgs) {
+		n.NotifyMediaStateChange(outer, e.State())
+	})
+}
+
+// Inner returns the inner media.
+func (w *GXMediaWrapper) Inner() IGXMedia {
+	return w.inner
+}
+
+// SetByteOrder sets the byte order of the wrapper and the inner media.
+func (w *GXMediaWrapper) SetByteOrder(value binary.ByteOrder) {
+	w.GXMediaBase.SetByteOrder(value)
+	if it, ok := w.inner.(byteOrderer); ok {
+		it.SetByteOrder(value)
+	}
+}
+
+// syncByteOrder copies the byte order of the inner media to the wrapper.
+func (w *GXMediaWrapper) syncByteOrder() {
+	if it, ok := w.inner.(byteOrderer); ok {
+		w.GXMediaBase.SetByteOrder(it.GetByteOrder())
+	}
+}
+
+// Send converts data to bytes with ToBytes using the byte order of the
+// wrapper and sends them with the inner media.
+func (w *GXMediaWrapper) Send(data any, receiver string) error {
+	b, err := ToBytes(data, w.GetByteOrder())
+	if err != nil {
+		return err
+	}
+	return w.SendBytes(b, receiver)
+}
+
+// SendBytes sends data with the inner media and updates the sent byte
+// counter and trace.
+func (w *GXMediaWrapper) SendBytes(data []byte, receiver string) error {
+	if err := w.inner.Send(data, receiver); err != nil {
+		return err
+	}
+	w.NotifySent(w.outer, data, receiver)
+	return nil
+}
+
+// Copy copies the configuration of the inner media to target. If target is
+// a wrapper, the configuration is copied to its inner media. The trace
+// level, EOP and byte order of the wrapper are then copied to target.
+func (w *GXMediaWrapper) Copy(target IGXMedia) error {
+	inner := target
+	if it, ok := target.(interface{ Inner() IGXMedia }); ok {
+		inner = it.Inner()
+	}
+	if err := w.inner.Copy(inner); err != nil {
+		return err
+	}
+	if err := target.SetTrace(w.GetTrace()); err != nil {
+		return err
+	}
+	target.SetEop(w.GetEop())
+	if it, ok := target.(byteOrderer); ok {
+		it.SetByteOrder(w.GetByteOrder())
+	}
+	return nil
+}
+
+// GetName returns the name of the inner media.
+func (w *GXMediaWrapper) GetName() string {
+	return w.inner.GetName()
+}
+
+// Open opens the inner media.
+func (w *GXMediaWrapper) Open() error {
+	return w.inner.Open()
+}
+
+// IsOpen reports whether the inner media is open.
+func (w *GXMediaWrapper) IsOpen() bool {
+	return w.inner.IsOpen()
+}
+
+// Close closes the inner media.
+func (w *GXMediaWrapper) Close() error {
+	return w.inner.Close()
+}
+
+// GetMediaType returns the media type of the inner media.
+func (w *GXMediaWrapper) GetMediaType() string {
+	return w.inner.GetMediaType()
+}
+
+// GetSettings returns the settings of the inner media.
+func (w *GXMediaWrapper) GetSettings() string {
+	return w.inner.GetSettings()
+}
+
+// SetSettings applies settings to the inner media.
+func (w *GXMediaWrapper) SetSettings(value string) error {
+	if err := w.inner.SetSettings(value); err != nil {
+		return err
+	}
+	w.syncByteOrder()
+	return nil
+}
+
+// Validate validates the settings of the inner media.
+func (w *GXMediaWrapper) Validate() error {
+	return w.inner.Validate()
+}
@@ -0,0 +1,126 @@
+package gxcommon
+
+// --------------------------------------------------------------------------
+//
+//	Gurux Ltd
+//
+// Filename:        $HeadURL$
+//
+// Version:         $Revision$,
+//
+//	$Date$
+//	$Author$
+//
+// # Copyright (c) Gurux Ltd
+//
+// ---------------------------------------------------------------------------
+//
+//	DESCRIPTION
+//
+// This file is a part of Gurux Device Framework.
+//
+// Gurux Device Framework is Open Source software; you can redistribute it
+// and/or modify it under the terms of the GNU General Public License
+// as published by the Free Software Foundation; version 2 of the License.
+// Gurux Device Framework is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.
+// See the GNU General Public License for more details.
+//
+// More information of Gurux products: https://www.gurux.org
+//
+// This code is licensed under the GNU General Public License v2.
+// Full text may be retrieved at http://www.gnu.org/licenses/gpl-2.0.txt
+// ---------------------------------------------------------------------------
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// Handshake defines flow-control modes for serial communication.
+//
+// The zero value is HandshakeNone.
+type Handshake int
+
+const (
+	// HandshakeNone indicates that flow control is not used.
+	HandshakeNone Handshake = iota
+	// HandshakeRtsCts indicates hardware flow control with the RTS and CTS
+	// lines.
+	HandshakeRtsCts
+	// HandshakeXonXoff indicates software flow control with the XON and XOFF
+	// characters.
+	HandshakeXonXoff
+)
+
+// HandshakeParse converts a flow-control name to Handshake.
+//
+// Accepted values are "None", "RtsCts", and "XonXoff" (case-insensitive).
+// The forms "RTS/CTS", "RTS-CTS", "XON/XOFF", and "XON-XOFF" are also
+// accepted.
+//
+// It returns ErrUnknownEnum if value does not match a supported mode.
+func HandshakeParse(value string) (Handshake, error) {
+	var ret Handshake
+	var err error
+	switch name := strings.NewReplacer("/", "", "-", "").Replace(value); {
+	case strings.EqualFold(name, "None"):
+		ret = HandshakeNone
+	case strings.EqualFold(name, "RtsCts"):
+		ret = HandshakeRtsCts
+	case strings.EqualFold(name, "XonXoff"):
+		ret = HandshakeXonXoff
+	default:
+		err = fmt.Errorf("%w: %q", ErrUnknownEnum, value)
+	}
+	return ret, err
+}
+
+// String returns the canonical flow-control name.
+//
+// It returns an empty string if g is not a defined Handshake value.
+// String satisfies fmt.Stringer.
+func (g Handshake) String() string {
+	var ret string
+	switch g {
+	case HandshakeNone:
+		ret = "None"
+	case HandshakeRtsCts:
+		ret = "RtsCts"
+	case HandshakeXonXoff:
+		ret = "XonXoff"
+	}
+	return ret
+}
+
+// MarshalText returns the canonical flow-control name.
+// It satisfies encoding.TextMarshaler.
+func (g Handshake) MarshalText() ([]byte, error) {
+	ret := g.String()
+	if ret == "" {
+		return nil, ErrUnknownEnumError(strconv.Itoa(int(g)))
+	}
+	return []byte(ret), nil
+}
+
+// UnmarshalText parses a flow-control name with HandshakeParse.
+// It satisfies encoding.TextUnmarshaler.
+func (g *Handshake) UnmarshalText(text []byte) error {
+	ret, err := HandshakeParse(string(text))
+	if err != nil {
+		return err
+	}
+	*g = ret
+	return nil
+}
+
+// AllHandshake returns all defined Handshake values in declaration order.
+func AllHandshake() []Handshake {
+	return []Handshake{
+		HandshakeNone,
+		HandshakeRtsCts,
+		HandshakeXonXoff,
+	}
+}
@@ -0,0 +1,187 @@
+package gxcommon
+
+// --------------------------------------------------------------------------
+//
+//	Gurux Ltd
+//
+// Filename:        $HeadURL$
+//
+// Version:         $Revision$,
+//
+//	$Date$
+//	$Author$
+//
+// # Copyright (c) Gurux Ltd
+//
+// ---------------------------------------------------------------------------
+//
+//	DESCRIPTION
+//
+// This file is a part of Gurux Device Framework.
+//
+// Gurux Device Framework is Open Source software; you can redistribute it
+// and/or modify it under the terms of the GNU General Public License
+// as published by the Free Software Foundation; version 2 of the License.
+// Gurux Device Framework is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.
+// See the GNU General Public License for more details.
+//
+// More information of Gurux products: https://www.gurux.org
+//
+// This code is licensed under the GNU General Public License v2.
+// Full text may be retrieved at http://www.gnu.org/licenses/gpl-2.0.txt
+// ---------------------------------------------------------------------------
+
+import (
+	"context"
+	"time"
+)
+
+// receivePollInterval is the longest time MediaContext waits in a single
+// Receive call of a media that does not support contexts.
+const receivePollInterval = 100 * time.Millisecond
+
+// IGXMediaContext is an optional interface for medias that support
+// cancellation with context.Context.
+type IGXMediaContext interface {
+	// OpenContext opens the media connection.
+	OpenContext(ctx context.Context) error
+
+	// SendContext transmits data.
+	// receiver contains media-specific receiver information and may be empty.
+	SendContext(ctx context.Context, data any, receiver string) error
+
+	// ReceiveContext waits for reply data according to args.
+	// It returns ctx.Err() if ctx is done before the reply is received.
+	ReceiveContext(ctx context.Context, args *ReceiveParameters) (bool, error)
+}
+
+// contextReceiver is implemented by medias that embed GXMediaBase.
+type contextReceiver interface {
+	ReceiveContext(ctx context.Context, args *ReceiveParameters) (bool, error)
+}
+
+// mediaContext adapts IGXMedia to IGXMediaContext.
+type mediaContext struct {
+	IGXMedia
+}
+
+// MediaContext returns media as IGXMediaContext.
+//
+// If media implements IGXMediaContext, it is returned as is. Otherwise the
+// returned value is an IGXMedia that forwards all calls to media and adds
+// the context-aware methods:
+//
+//   - OpenContext and SendContext return ctx.Err() when ctx is done, but the
+//     operation itself is not interrupted. A connection that is opened after
+//     cancellation is closed.
+//   - ReceiveContext uses the ReceiveContext method of media if it has one,
+//     as medias that embed GXMediaBase do. Otherwise the wait time is split
+//     into short Receive calls so that cancellation is noticed.
+func MediaContext(media IGXMedia) IGXMediaContext {
+	if ret, ok := media.(IGXMediaContext); ok {
+		return ret
+	}
+	return &mediaContext{IGXMedia: media}
+}
+
+// OpenContext opens the media connection.
+func (m *mediaContext) OpenContext(ctx context.Context) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	done := make(chan error, 1)
+	go func() {
+		done <- m.Open()
+	}()
+	select {
+	case err := <-done:
+		return err
+	case <-ctx.Done():
+		go func() {
+			if <-done == nil {
+				_ = m.Close()
+			}
+		}()
+		return ctx.Err()
+	}
+}
+
+// SendContext transmits data.
+func (m *mediaContext) SendContext(ctx context.Context, data any, receiver string) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	done := make(chan error, 1)
+	go func() {
+		done <- m.Send(data, receiver)
+	}()
+	select {
+	case err := <-done:
+		return err
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// ReceiveContext waits for reply data according to args.
+func (m *mediaContext) ReceiveContext(ctx context.Context, args *ReceiveParameters) (bool, error) {
+	if r, ok := m.IGXMedia.(contextReceiver); ok {
+		return r.ReceiveContext(ctx, args)
+	}
+	if err := ctx.Err(); err != nil {
+		return false, err
+	}
+	if args == nil || args.WaitTime == 0 {
+		return m.Receive(args)
+	}
+	// Wait for the reply with peek so that the data is left to the
+	// buffer, and read it with the original arguments when it is available.
+	p := *args
+	p.Peek = true
+	p.AllData = false
+	p.Count = byteCount(args.Count, replyType(args))
+	p.ReplyType = DataTypeBytes
+	p.Reply = nil
+	if p.Framer == nil && p.EOP == nil && p.Count <= 0 {
+		p.Count = 1
+	}
+	var deadline time.Time
+	if args.WaitTime > 0 {
+		deadline = time.Now().Add(time.Duration(args.WaitTime) * time.Millisecond)
+	}
+	ctxDeadline, hasCtxDeadline := ctx.Deadline()
+	for {
+		wait := receivePollInterval
+		if !deadline.IsZero() {
+			wait = min(wait, time.Until(deadline))
+		}
+		if hasCtxDeadline {
+			// Do not wait past the deadline of ctx.
+			wait = min(wait, time.Until(ctxDeadline))
+		}
+		p.WaitTime = max(int(wait/time.Millisecond), 1)
+		ret, err := m.Receive(&p)
+		if err != nil {
+			return false, err
+		}
+		if ret {
+			break
+		}
+		if err = ctx.Err(); err != nil {
+			return false, err
+		}
+		if !deadline.IsZero() && !time.Now().Before(deadline) {
+			if !args.AllData {
+				return false, nil
+			}
+			break
+		}
+	}
+	p = *args
+	p.WaitTime = 0
+	ret, err := m.Receive(&p)
+	args.Reply = p.Reply
+	return ret, err
+}
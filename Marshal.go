@@ -0,0 +1,556 @@
+package gxcommon
+
+// --------------------------------------------------------------------------
+//
+//	Gurux Ltd
+//
+// Filename:        $HeadURL$
+//
+// Version:         $Revision$,
+//
+//	$Date$
+//	$Author$
+//
+// # Copyright (c) Gurux Ltd
+//
+// ---------------------------------------------------------------------------
+//
+//	DESCRIPTION
+//
+// This file is a part of Gurux Device Framework.
+//
+// Gurux Device Framework is Open Source software; you can redistribute it
+// and/or modify it under the terms of the GNU General Public License
+// as published by the Free Software Foundation; version 2 of the License.
+// Gurux Device Framework is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.
+// See the GNU General Public License for more details.
+//
+// More information of Gurux products: https://www.gurux.org
+//
+// This code is licensed under the GNU General Public License v2.
+// Full text may be retrieved at http://www.gnu.org/licenses/gpl-2.0.txt
+// ---------------------------------------------------------------------------
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+)
+
+// fieldTag holds the options of a gx struct tag.
+type fieldTag struct {
+	order  binary.ByteOrder
+	length int
+	pad    int
+	prefix int
+	bcd    bool
+	skip   bool
+}
+
+// Marshal encodes the exported fields of struct v in declaration order.
+//
+// Fields are encoded like ToBytes with order as the default byte order. The
+// encoding of a field can be changed with options in a gx struct tag,
+// separated with commas:
+//
+//   - order=big or order=little sets the byte order of the field. Other
+//     names accepted by ByteOrderParse, such as order=cdab, are also
+//     accepted.
+//   - len=N sets the length of a string or byte slice in bytes, or the number
+//     of elements of another slice. Shorter values are padded with zeros and
+//     trailing zeros are removed from decoded strings.
+//   - prefix=N writes the length of a string or byte slice, or the number of
+//     elements of another slice, as an N byte unsigned integer (1, 2 or 4)
+//     before the value.
+//   - pad=N adds N zero bytes after the field.
+//   - bcd encodes an unsigned integer as packed BCD. It requires len=N, where
+//     N is the number of bytes.
+//   - "-" skips the field.
+//
+// A string or slice without len or prefix is decoded from the rest of the
+// data, so it must be the last field. Supported field types are bool,
+// integers, floats, strings, slices, arrays and structs of them. int and uint
+// are encoded as 8 bytes. Blank fields of array type, such as _ [2]byte, are
+// encoded as zero bytes and skipped when decoding.
+//
+// It returns ErrInvalidArgument if v is not a struct or a pointer to a struct,
+// if a tag is invalid or a field type is not supported, and
+// ErrArgumentOutOfRange if a value does not fit to its field.
+func Marshal(v any, order binary.ByteOrder) ([]byte, error) {
+	rv := reflect.Indirect(reflect.ValueOf(v))
+	if rv.Kind() != reflect.Struct {
+		return nil, ErrInvalidArgumentError("v")
+	}
+	return marshalStruct(nil, rv, order, "")
+}
+
+// Unmarshal decodes data to the struct pointed by v. The encoding is
+// described in Marshal. Data after the last field is ignored.
+//
+// It returns ErrBufferTooSmall with the name of the field if data ends
+// before all fields are decoded, and ErrInvalidArgument if v is not a
+// pointer to a struct, if a tag is invalid, if a field type is not supported,
+// if a BCD field has invalid digits or if a slice without a length has
+// elements that use no bytes.
+func Unmarshal(data []byte, v any, order binary.ByteOrder) error {
+	rv := reflect.ValueOf(v)
+	if rv.Kind() != reflect.Pointer || rv.IsNil() || rv.Elem().Kind() != reflect.Struct {
+		return ErrInvalidArgumentError("v")
+	}
+	_, err := unmarshalStruct(data, rv.Elem(), order, "")
+	return err
+}
+
+// parseTag parses the gx tag of field name.
+func parseTag(tag string, order binary.ByteOrder, name string) (fieldTag, error) {
+	ret := fieldTag{order: order}
+	if tag == "" {
+		return ret, nil
+	}
+	if tag == "-" {
+		ret.skip = true
+		return ret, nil
+	}
+	for _, it := range strings.Split(tag, ",") {
+		key, value, _ := strings.Cut(strings.TrimSpace(it), "=")
+		var err error
+		switch key {
+		case "order":
+			switch value {
+			case "big":
+				ret.order = binary.BigEndian
+			case "little":
+				ret.order = binary.LittleEndian
+			default:
+				ret.order, err = ByteOrderParse(value)
+			}
+		case "len":
+			ret.length, err = strconv.Atoi(value)
+			if err == nil && ret.length <= 0 {
+				err = ErrInvalidArgument
+			}
+		case "pad":
+			ret.pad, err = strconv.Atoi(value)
+			if err == nil && ret.pad < 0 {
+				err = ErrInvalidArgument
+			}
+		case "prefix":
+			ret.prefix, err = strconv.Atoi(value)
+			if err == nil && ret.prefix != 1 && ret.prefix != 2 && ret.prefix != 4 {
+				err = ErrInvalidArgument
+			}
+		case "bcd":
+			ret.bcd = value == ""
+			if !ret.bcd {
+				err = ErrInvalidArgument
+			}
+		default:
+			err = ErrInvalidArgument
+		}
+		if err != nil {
+			return ret, ErrInvalidArgumentError(fmt.Sprintf("gx tag %q of %s", tag, name))
+		}
+	}
+	if ret.length != 0 && ret.prefix != 0 || ret.bcd && ret.length == 0 {
+		return ret, ErrInvalidArgumentError(fmt.Sprintf("gx tag %q of %s", tag, name))
+	}
+	return ret, nil
+}
+
+// fieldName returns the name of a field for error messages.
+func fieldName(parent string, name string) string {
+	if parent == "" {
+		return name
+	}
+	return parent + "." + name
+}
+
+// scalarType returns the DataType that encodes a value of kind k or
+// DataTypeUnknown if k is not a fixed-size scalar.
+func scalarType(k reflect.Kind) DataType {
+	switch k {
+	case reflect.Bool:
+		return DataTypeBool
+	case reflect.Int8:
+		return DataTypeInt8
+	case reflect.Int16:
+		return DataTypeInt16
+	case reflect.Int32:
+		return DataTypeInt32
+	case reflect.Int64:
+		return DataTypeInt64
+	case reflect.Int:
+		return DataTypeInt
+	case reflect.Uint8:
+		return DataTypeUint8
+	case reflect.Uint16:
+		return DataTypeUint16
+	case reflect.Uint32:
+		return DataTypeUint32
+	case reflect.Uint64:
+		return DataTypeUint64
+	case reflect.Uint:
+		return DataTypeUint
+	case reflect.Float32:
+		return DataTypeFloat32
+	case reflect.Float64:
+		return DataTypeFloat64
+	default:
+		return DataTypeUnknown
+	}
+}
+
+// goTypes are the Go types of the scalar DataTypes.
+var goTypes = map[DataType]reflect.Type{
+	DataTypeBool:    reflect.TypeFor[bool](),
+	DataTypeInt8:    reflect.TypeFor[int8](),
+	DataTypeInt16:   reflect.TypeFor[int16](),
+	DataTypeInt32:   reflect.TypeFor[int32](),
+	DataTypeInt64:   reflect.TypeFor[int64](),
+	DataTypeInt:     reflect.TypeFor[int](),
+	DataTypeUint8:   reflect.TypeFor[uint8](),
+	DataTypeUint16:  reflect.TypeFor[uint16](),
+	DataTypeUint32:  reflect.TypeFor[uint32](),
+	DataTypeUint64:  reflect.TypeFor[uint64](),
+	DataTypeUint:    reflect.TypeFor[uint](),
+	DataTypeFloat32: reflect.TypeFor[float32](),
+	DataTypeFloat64: reflect.TypeFor[float64](),
+}
+
+// marshalStruct appends the fields of v to buf.
+func marshalStruct(buf []byte, v reflect.Value, order binary.ByteOrder, name string) ([]byte, error) {
+	t := v.Type()
+	for i := range t.NumField() {
+		f := t.Field(i)
+		fname := fieldName(name, f.Name)
+		if !f.IsExported() {
+			if f.Name == "_" && f.Type.Kind() == reflect.Array {
+				size := binary.Size(reflect.Zero(f.Type).Interface())
+				if size < 0 {
+					return nil, ErrInvalidArgumentError(fname)
+				}
+				buf = append(buf, make([]byte, size)...)
+			}
+			continue
+		}
+		tag, err := parseTag(f.Tag.Get("gx"), order, fname)
+		if err != nil {
+			return nil, err
+		}
+		if tag.skip {
+			continue
+		}
+		if buf, err = marshalValue(buf, v.Field(i), tag, fname); err != nil {
+			return nil, err
+		}
+		buf = append(buf, make([]byte, tag.pad)...)
+	}
+	return buf, nil
+}
+
+// marshalValue appends v to buf.
+func marshalValue(buf []byte, v reflect.Value, tag fieldTag, name string) ([]byte, error) {
+	if tag.bcd {
+		var value uint64
+		switch {
+		case v.CanUint():
+			value = v.Uint()
+		case v.CanInt() && v.Int() >= 0:
+			value = uint64(v.Int())
+		case v.CanInt():
+			return nil, ErrArgumentOutOfRangeError(name)
+		default:
+			return nil, ErrInvalidArgumentError(name)
+		}
+		ret, err := AppendBCD(buf, value, tag.length*2)
+		if err != nil {
+			return nil, ErrArgumentOutOfRangeError(name)
+		}
+		return ret, nil
+	}
+	if dt := scalarType(v.Kind()); dt != DataTypeUnknown {
+		return AppendBytes(buf, v.Convert(goTypes[dt]).Interface(), tag.order)
+	}
+	switch v.Kind() {
+	case reflect.String:
+		return marshalBytes(buf, []byte(v.String()), tag, name)
+	case reflect.Slice:
+		if v.Type().Elem().Kind() == reflect.Uint8 {
+			return marshalBytes(buf, v.Bytes(), tag, name)
+		}
+		count := v.Len()
+		var err error
+		if buf, err = marshalLength(buf, count, tag, name); err != nil {
+			return nil, err
+		}
+		elem := fieldTag{order: tag.order}
+		for i := range count {
+			if buf, err = marshalValue(buf, v.Index(i), elem, fmt.Sprintf("%s[%d]", name, i)); err != nil {
+				return nil, err
+			}
+		}
+		if tag.length != 0 {
+			zero := reflect.Zero(v.Type().Elem())
+			for i := count; i < tag.length; i++ {
+				if buf, err = marshalValue(buf, zero, elem, fmt.Sprintf("%s[%d]", name, i)); err != nil {
+					return nil, err
+				}
+			}
+		}
+		return buf, nil
+	case reflect.Array:
+		elem := fieldTag{order: tag.order}
+		var err error
+		for i := range v.Len() {
+			if buf, err = marshalValue(buf, v.Index(i), elem, fmt.Sprintf("%s[%d]", name, i)); err != nil {
+				return nil, err
+			}
+		}
+		return buf, nil
+	case reflect.Struct:
+		return marshalStruct(buf, v, tag.order, name)
+	default:
+		return nil, ErrInvalidArgumentError(name)
+	}
+}
+
+// marshalBytes appends the bytes of a string or byte slice to buf.
+func marshalBytes(buf []byte, b []byte, tag fieldTag, name string) ([]byte, error) {
+	buf, err := marshalLength(buf, len(b), tag, name)
+	if err != nil {
+		return nil, err
+	}
+	buf = append(buf, b...)
+	if tag.length != 0 {
+		buf = append(buf, make([]byte, tag.length-len(b))...)
+	}
+	return buf, nil
+}
+
+// marshalLength appends the length prefix to buf or checks that count fits
+// to the fixed length.
+func marshalLength(buf []byte, count int, tag fieldTag, name string) ([]byte, error) {
+	if tag.length != 0 {
+		if count > tag.length {
+			return nil, ErrArgumentOutOfRangeError(name)
+		}
+		return buf, nil
+	}
+	switch tag.prefix {
+	case 1:
+		if count > 0xFF {
+			return nil, ErrArgumentOutOfRangeError(name)
+		}
+		buf = append(buf, byte(count))
+	case 2:
+		if count > 0xFFFF {
+			return nil, ErrArgumentOutOfRangeError(name)
+		}
+		b, err := ToBytes(uint16(count), tag.order)
+		if err != nil {
+			return nil, err
+		}
+		buf = append(buf, b...)
+	case 4:
+		if uint64(count) > 0xFFFFFFFF {
+			return nil, ErrArgumentOutOfRangeError(name)
+		}
+		b, err := ToBytes(uint32(count), tag.order)
+		if err != nil {
+			return nil, err
+		}
+		buf = append(buf, b...)
+	}
+	return buf, nil
+}
+
+// unmarshalStruct decodes the fields of v from data and returns the number
+// of bytes used.
+func unmarshalStruct(data []byte, v reflect.Value, order binary.ByteOrder, name string) (int, error) {
+	t := v.Type()
+	pos := 0
+	for i := range t.NumField() {
+		f := t.Field(i)
+		fname := fieldName(name, f.Name)
+		if !f.IsExported() {
+			if f.Name == "_" && f.Type.Kind() == reflect.Array {
+				size := binary.Size(reflect.Zero(f.Type).Interface())
+				if size < 0 {
+					return 0, ErrInvalidArgumentError(fname)
+				}
+				if len(data)-pos < size {
+					return 0, ErrBufferTooSmallError(fname)
+				}
+				pos += size
+			}
+			continue
+		}
+		tag, err := parseTag(f.Tag.Get("gx"), order, fname)
+		if err != nil {
+			return 0, err
+		}
+		if tag.skip {
+			continue
+		}
+		n, err := unmarshalValue(data[pos:], v.Field(i), tag, fname)
+		if err != nil {
+			return 0, err
+		}
+		pos += n
+		if len(data)-pos < tag.pad {
+			return 0, ErrBufferTooSmallError(fname)
+		}
+		pos += tag.pad
+	}
+	return pos, nil
+}
+
+// unmarshalValue decodes v from data and returns the number of bytes used.
+func unmarshalValue(data []byte, v reflect.Value, tag fieldTag, name string) (int, error) {
+	if tag.bcd {
+		if !v.CanUint() && !v.CanInt() {
+			return 0, ErrInvalidArgumentError(name)
+		}
+		if len(data) < tag.length {
+			return 0, ErrBufferTooSmallError(name)
+		}
+		value, err := ParseBCD(data[:tag.length])
+		if errors.Is(err, ErrArgumentOutOfRange) {
+			return 0, ErrArgumentOutOfRangeError(name)
+		}
+		if err != nil {
+			return 0, ErrInvalidArgumentError(name)
+		}
+		if v.CanUint() {
+			if v.OverflowUint(value) {
+				return 0, ErrArgumentOutOfRangeError(name)
+			}
+			v.SetUint(value)
+		} else {
+			if value > 1<<63-1 || v.OverflowInt(int64(value)) {
+				return 0, ErrArgumentOutOfRangeError(name)
+			}
+			v.SetInt(int64(value))
+		}
+		return tag.length, nil
+	}
+	if dt := scalarType(v.Kind()); dt != DataTypeUnknown {
+		size := dt.ElementSize()
+		if len(data) < size {
+			return 0, ErrBufferTooSmallError(name)
+		}
+		value, err := BytesToAny2(data[:size], dt, tag.order)
+		if err != nil {
+			return 0, err
+		}
+		v.Set(reflect.ValueOf(value).Convert(v.Type()))
+		return size, nil
+	}
+	switch v.Kind() {
+	case reflect.String:
+		b, n, err := unmarshalBytes(data, tag, name)
+		if err != nil {
+			return 0, err
+		}
+		if tag.length != 0 {
+			b = []byte(strings.TrimRight(string(b), "\x00"))
+		}
+		v.SetString(string(b))
+		return n, nil
+	case reflect.Slice:
+		if v.Type().Elem().Kind() == reflect.Uint8 {
+			b, n, err := unmarshalBytes(data, tag, name)
+			if err != nil {
+				return 0, err
+			}
+			v.SetBytes(append([]byte{}, b...))
+			return n, nil
+		}
+		count, pos, err := unmarshalLength(data, tag, name)
+		if err != nil {
+			return 0, err
+		}
+		elem := fieldTag{order: tag.order}
+		ret := reflect.MakeSlice(v.Type(), 0, max(count, 0))
+		for i := 0; count == -1 && pos < len(data) || i < count; i++ {
+			it := reflect.New(v.Type().Elem()).Elem()
+			ename := fmt.Sprintf("%s[%d]", name, i)
+			n, err := unmarshalValue(data[pos:], it, elem, ename)
+			if err != nil {
+				return 0, err
+			}
+			if n == 0 && count == -1 {
+				// Elements that use no bytes would never reach the end
+				// of data.
+				return 0, ErrInvalidArgumentError(ename)
+			}
+			pos += n
+			ret = reflect.Append(ret, it)
+		}
+		v.Set(ret)
+		return pos, nil
+	case reflect.Array:
+		elem := fieldTag{order: tag.order}
+		pos := 0
+		for i := range v.Len() {
+			n, err := unmarshalValue(data[pos:], v.Index(i), elem, fmt.Sprintf("%s[%d]", name, i))
+			if err != nil {
+				return 0, err
+			}
+			pos += n
+		}
+		return pos, nil
+	case reflect.Struct:
+		return unmarshalStruct(data, v, tag.order, name)
+	default:
+		return 0, ErrInvalidArgumentError(name)
+	}
+}
+
+// unmarshalBytes returns the bytes of a string or byte slice and the number
+// of bytes used.
+func unmarshalBytes(data []byte, tag fieldTag, name string) ([]byte, int, error) {
+	count, pos, err := unmarshalLength(data, tag, name)
+	if err != nil {
+		return nil, 0, err
+	}
+	if count == -1 {
+		count = len(data)
+	}
+	if len(data)-pos < count {
+		return nil, 0, ErrBufferTooSmallError(name)
+	}
+	return data[pos : pos+count], pos + count, nil
+}
+
+// unmarshalLength returns the fixed or prefixed length and the size of the
+// prefix. The length is -1 if the value continues to the end of data.
+func unmarshalLength(data []byte, tag fieldTag, name string) (int, int, error) {
+	if tag.length != 0 {
+		return tag.length, 0, nil
+	}
+	if tag.prefix == 0 {
+		return -1, 0, nil
+	}
+	if len(data) < tag.prefix {
+		return 0, 0, ErrBufferTooSmallError(name)
+	}
+	var ret uint64
+	switch tag.prefix {
+	case 1:
+		ret = uint64(data[0])
+	case 2:
+		ret = uint64(tag.order.Uint16(data))
+	default:
+		ret = uint64(tag.order.Uint32(data))
+	}
+	if ret > uint64(len(data)) {
+		return 0, 0, ErrBufferTooSmallError(name)
+	}
+	return int(ret), tag.prefix, nil
+}
@@ -0,0 +1,108 @@
+package gxcommon
+
+// --------------------------------------------------------------------------
+//
+//	Gurux Ltd
+//
+// Filename:        $HeadURL$
+//
+// Version:         $Revision$,
+//
+//	$Date$
+//	$Author$
+//
+// # Copyright (c) Gurux Ltd
+//
+// ---------------------------------------------------------------------------
+//
+//	DESCRIPTION
+//
+// This file is a part of Gurux Device Framework.
+//
+// Gurux Device Framework is Open Source software; you can redistribute it
+// and/or modify it under the terms of the GNU General Public License
+// as published by the Free Software Foundation; version 2 of the License.
+// Gurux Device Framework is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.
+// See the GNU General Public License for more details.
+//
+// More information of Gurux products: https://www.gurux.org
+//
+// This code is licensed under the GNU General Public License v2.
+// Full text may be retrieved at http://www.gnu.org/licenses/gpl-2.0.txt
+// ---------------------------------------------------------------------------
+
+import (
+	"slices"
+	"strings"
+	"sync"
+)
+
+// MediaFactory creates a new media with default settings.
+type MediaFactory func() IGXMedia
+
+var (
+	mediasMu sync.RWMutex
+	// medias maps lower case media type names to registrations.
+	medias = map[string]mediaRegistration{}
+)
+
+// mediaRegistration is a registered media type.
+type mediaRegistration struct {
+	mediaType string
+	factory   MediaFactory
+}
+
+// RegisterMedia makes a media type available for NewMedia.
+//
+// mediaType should be the value returned by GetMediaType of the created
+// medias. Media types are matched case-insensitively. Media packages usually
+// call RegisterMedia from an init function.
+//
+// RegisterMedia panics if factory is nil or if mediaType is already
+// registered.
+func RegisterMedia(mediaType string, factory MediaFactory) {
+	if factory == nil {
+		panic("gxcommon: RegisterMedia factory is nil")
+	}
+	key := strings.ToLower(mediaType)
+	mediasMu.Lock()
+	defer mediasMu.Unlock()
+	if _, ok := medias[key]; ok {
+		panic("gxcommon: RegisterMedia called twice for media type " + mediaType)
+	}
+	medias[key] = mediaRegistration{mediaType: mediaType, factory: factory}
+}
+
+// NewMedia creates a media of the given registered type and applies settings
+// with SetSettings. Settings are not applied if settings is empty.
+//
+// It returns ErrInvalidArgument if mediaType is not registered.
+func NewMedia(mediaType string, settings string) (IGXMedia, error) {
+	mediasMu.RLock()
+	r, ok := medias[strings.ToLower(mediaType)]
+	mediasMu.RUnlock()
+	if !ok {
+		return nil, ErrInvalidArgumentError(mediaType)
+	}
+	ret := r.factory()
+	if settings != "" {
+		if err := ret.SetSettings(settings); err != nil {
+			return nil, err
+		}
+	}
+	return ret, nil
+}
+
+// MediaTypes returns the registered media types in sorted order.
+func MediaTypes() []string {
+	mediasMu.RLock()
+	ret := make([]string, 0, len(medias))
+	for _, it := range medias {
+		ret = append(ret, it.mediaType)
+	}
+	mediasMu.RUnlock()
+	slices.Sort(ret)
+	return ret
+}
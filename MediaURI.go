@@ -0,0 +1,181 @@
+package gxcommon
+
+// --------------------------------------------------------------------------
+//
+//	Gurux Ltd
+//
+// Filename:        $HeadURL$
+//
+// Version:         $Revision$,
+//
+//	$Date$
+//	$Author$
+//
+// # Copyright (c) Gurux Ltd
+//
+// ---------------------------------------------------------------------------
+//
+//	DESCRIPTION
+//
+// This file is a part of Gurux Device Framework.
+//
+// Gurux Device Framework is Open Source software; you can redistribute it
+// and/or modify it under the terms of the GNU General Public License
+// as published by the Free Software Foundation; version 2 of the License.
+// Gurux Device Framework is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.
+// See the GNU General Public License for more details.
+//
+// More information of Gurux products: https://www.gurux.org
+//
+// This code is licensed under the GNU General Public License v2.
+// Full text may be retrieved at http://www.gnu.org/licenses/gpl-2.0.txt
+// ---------------------------------------------------------------------------
+
+import (
+	"net/url"
+	"strings"
+)
+
+// MediaURI describes a media connection as a URI.
+//
+// The scheme is the media type and query parameters hold the settings, for
+// example:
+//
+//	serial:///dev/ttyUSB0?baud=9600&parity=even&stopbits=one
+//	tcp://10.0.0.5:4059?trace=verbose
+//
+// Supported query parameters are baud (BaudRateParse), databits
+// (DataBitsParse), parity (ParityParse), stopbits (StopBitsParse), handshake
+// (HandshakeParse) and trace (TraceLevelParse). Parameter names are
+// case-insensitive.
+type MediaURI struct {
+	// Scheme is the media type, for example "serial" or "tcp".
+	Scheme string
+
+	// Host is the host name and optional port of network medias.
+	Host string
+
+	// Path is the device path of serial medias.
+	Path string
+
+	// BaudRate is the baud rate or zero if it is not set.
+	BaudRate BaudRate
+
+	// DataBits is the number of data bits or zero if it is not set.
+	DataBits DataBits
+
+	// Parity is the parity mode.
+	Parity Parity
+
+	// StopBits is the stop-bit mode.
+	StopBits StopBits
+
+	// Handshake is the flow-control mode.
+	Handshake Handshake
+
+	// Trace is the trace level.
+	Trace TraceLevel
+}
+
+// ParseMediaURI parses a connection URI.
+//
+// It returns ErrInvalidArgument if value is not a valid URI, if the scheme
+// is missing, or if a query parameter is unknown, repeated or invalid.
+func ParseMediaURI(value string) (*MediaURI, error) {
+	u, err := url.Parse(value)
+	if err != nil {
+		return nil, ErrInvalidArgumentError(value)
+	}
+	if u.Scheme == "" {
+		return nil, ErrInvalidArgumentError("scheme")
+	}
+	ret := &MediaURI{
+		Scheme: strings.ToLower(u.Scheme),
+		Host:   u.Host,
+		Path:   u.Path,
+	}
+	if u.Opaque != "" {
+		ret.Path = u.Opaque
+	}
+	query, err := url.ParseQuery(u.RawQuery)
+	if err != nil {
+		return nil, ErrInvalidArgumentError(u.RawQuery)
+	}
+	for key, values := range query {
+		if len(values) != 1 {
+			return nil, ErrInvalidArgumentError(key)
+		}
+		if err = ret.setParameter(strings.ToLower(key), values[0]); err != nil {
+			return nil, err
+		}
+	}
+	return ret, nil
+}
+
+// setParameter sets the value of a query parameter.
+func (u *MediaURI) setParameter(key string, value string) error {
+	var err error
+	switch key {
+	case "baud":
+		u.BaudRate, err = BaudRateParse(value)
+	case "databits":
+		u.DataBits, err = DataBitsParse(value)
+	case "parity":
+		u.Parity, err = ParityParse(value)
+	case "stopbits":
+		u.StopBits, err = StopBitsParse(value)
+	case "handshake":
+		u.Handshake, err = HandshakeParse(value)
+	case "trace":
+		u.Trace, err = TraceLevelParse(value)
+	default:
+		return ErrInvalidArgumentError(key)
+	}
+	if err != nil {
+		return ErrInvalidArgumentError(key + "=" + value)
+	}
+	return nil
+}
+
+// String returns the canonical form of the URI.
+//
+// The scheme and parameter values are lower case, parameters are sorted by
+// name and parameters that have their zero value are omitted. A path that
+// does not start with a slash, such as COM1, is written without the
+// authority part: serial:COM1.
+func (u *MediaURI) String() string {
+	query := url.Values{}
+	if u.BaudRate != 0 {
+		query.Set("baud", u.BaudRate.String())
+	}
+	if u.DataBits != 0 {
+		query.Set("databits", u.DataBits.String())
+	}
+	if u.Parity != ParityNone {
+		query.Set("parity", strings.ToLower(u.Parity.String()))
+	}
+	if u.StopBits != StopBitsNone {
+		query.Set("stopbits", strings.ToLower(u.StopBits.String()))
+	}
+	if u.Handshake != HandshakeNone {
+		query.Set("handshake", strings.ToLower(u.Handshake.String()))
+	}
+	if u.Trace != TraceLevelOff {
+		query.Set("trace", strings.ToLower(u.Trace.String()))
+	}
+	ret := url.URL{
+		Scheme:   strings.ToLower(u.Scheme),
+		Host:     u.Host,
+		Path:     u.Path,
+		RawQuery: query.Encode(),
+	}
+	if u.Host == "" && u.Path != "" && !strings.HasPrefix(u.Path, "/") {
+		// A relative path such as COM1 is written as serial:COM1, so it
+		// is not parsed back as a host.
+		ret.Path = ""
+		ret.Opaque = u.Path
+	}
+	return ret.String()
+}
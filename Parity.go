@@ -35,6 +35,7 @@ package gxcommon
 
 import (
 	"fmt"
+	"strconv"
 	"strings"
 )
 
@@ -103,6 +104,27 @@ func (g Parity) String() string {
 	return ret
 }
 
+// MarshalText returns the canonical parity name.
+// It satisfies encoding.TextMarshaler.
+func (g Parity) MarshalText() ([]byte, error) {
+	ret := g.String()
+	if ret == "" {
+		return nil, ErrUnknownEnumError(strconv.Itoa(int(g)))
+	}
+	return []byte(ret), nil
+}
+
+// UnmarshalText parses a parity name with ParityParse.
+// It satisfies encoding.TextUnmarshaler.
+func (g *Parity) UnmarshalText(text []byte) error {
+	ret, err := ParityParse(string(text))
+	if err != nil {
+		return err
+	}
+	*g = ret
+	return nil
+}
+
 // AllParity returns all defined Parity values in declaration order.
 func AllParity() []Parity {
 	return []Parity{
@@ -1,7 +1,6 @@
 package gxcommon
 
 import (
-	"encoding/hex"
 	"fmt"
 	"strings"
 )
@@ -72,16 +71,22 @@ func (e *ReceiveEventArgs) String() string {
 	return fmt.Sprintf("%s\t%s", e.senderInfo, str)
 }
 
+// hexDigits are the uppercase hex digits.
+const hexDigits = "0123456789ABCDEF"
+
 // ToHex converts a byte slice to an uppercase, space-separated hex string.
 func ToHex(value []byte) string {
 	if len(value) == 0 {
 		return ""
 	}
-	out := make([]byte, len(value)*3-1)
-	hex.Encode(out[0:2], value[0:1])
-	for i := 1; i < len(value); i++ {
-		out[i*3-1] = ' '
-		hex.Encode(out[i*3:i*3+2], value[i:i+1])
+	var sb strings.Builder
+	sb.Grow(len(value)*3 - 1)
+	for i, it := range value {
+		if i != 0 {
+			sb.WriteByte(' ')
+		}
+		sb.WriteByte(hexDigits[it>>4])
+		sb.WriteByte(hexDigits[it&0x0F])
 	}
-	return strings.ToUpper(string(out))
+	return sb.String()
 }
@@ -0,0 +1,203 @@
+package gxcommon
+
+// --------------------------------------------------------------------------
+//
+//	Gurux Ltd
+//
+// Filename:        $HeadURL$
+//
+// Version:         $Revision$,
+//
+//	$Date$
+//	$Author$
+//
+// # Copyright (c) Gurux Ltd
+//
+// ---------------------------------------------------------------------------
+//
+//	DESCRIPTION
+//
+// This file is a part of Gurux Device Framework.
+//
+// Gurux Device Framework is Open Source software; you can redistribute it
+// and/or modify it under the terms of the GNU General Public License
+// as published by the Free Software Foundation; version 2 of the License.
+// Gurux Device Framework is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.
+// See the GNU General Public License for more details.
+//
+// More information of Gurux products: https://www.gurux.org
+//
+// This code is licensed under the GNU General Public License v2.
+// Full text may be retrieved at http://www.gnu.org/licenses/gpl-2.0.txt
+// ---------------------------------------------------------------------------
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// SerialSettings holds the line settings of a serial port.
+//
+// The compact notation is the baud rate followed by the character format,
+// separated with a comma or white space, for example "9600,7E1" or
+// "115200 8N1". The character format is the number of data bits, the parity
+// letter (N, O, E, M or S) and the number of stop bits (1, 1.5 or 2). It can
+// be followed by a flow-control name, for example "9600 8N1 RtsCts".
+//
+// SerialSettings can be marshaled to JSON and XML for configuration files.
+// Parity, stop bits and flow control are written by name.
+type SerialSettings struct {
+	// BaudRate is the communication speed.
+	BaudRate BaudRate `json:"baudRate" xml:"BaudRate"`
+
+	// DataBits is the number of data bits.
+	DataBits DataBits `json:"dataBits" xml:"DataBits"`
+
+	// Parity is the parity mode.
+	Parity Parity `json:"parity" xml:"Parity"`
+
+	// StopBits is the stop-bit mode.
+	StopBits StopBits `json:"stopBits" xml:"StopBits"`
+
+	// Handshake is the flow-control mode.
+	Handshake Handshake `json:"handshake" xml:"Handshake"`
+}
+
+// DefaultSerialSettings returns 9600 8N1 without flow control.
+func DefaultSerialSettings() SerialSettings {
+	return SerialSettings{
+		BaudRate: BaudRate9600,
+		DataBits: DataBits8,
+		Parity:   ParityNone,
+		StopBits: StopBitsOne,
+	}
+}
+
+// ParseSerialSettings parses serial settings in the compact notation.
+//
+// It returns ErrInvalidArgument if value is not in the compact notation,
+// ErrUnknownEnum if the baud rate or flow-control name is unknown, and the
+// error of Validate if the combination is not valid.
+func ParseSerialSettings(value string) (SerialSettings, error) {
+	var ret SerialSettings
+	fields := strings.FieldsFunc(value, func(r rune) bool {
+		return r == ',' || r == ' ' || r == '\t'
+	})
+	if len(fields) != 2 && len(fields) != 3 {
+		return ret, ErrInvalidArgumentError(value)
+	}
+	var err error
+	if ret.BaudRate, err = BaudRateParse(fields[0]); err != nil {
+		return ret, err
+	}
+	format := fields[1]
+	if len(format) < 3 {
+		return ret, ErrInvalidArgumentError(format)
+	}
+	if ret.DataBits, err = DataBitsParse(format[:1]); err != nil {
+		return ret, ErrInvalidArgumentError(format)
+	}
+	switch format[1] {
+	case 'N', 'n':
+		ret.Parity = ParityNone
+	case 'O', 'o':
+		ret.Parity = ParityOdd
+	case 'E', 'e':
+		ret.Parity = ParityEven
+	case 'M', 'm':
+		ret.Parity = ParityMark
+	case 'S', 's':
+		ret.Parity = ParitySpace
+	default:
+		return ret, ErrInvalidArgumentError(format)
+	}
+	switch format[2:] {
+	case "1":
+		ret.StopBits = StopBitsOne
+	case "1.5":
+		ret.StopBits = StopBitsOnePointFive
+	case "2":
+		ret.StopBits = StopBitsTwo
+	default:
+		return ret, ErrInvalidArgumentError(format)
+	}
+	if len(fields) == 3 {
+		if ret.Handshake, err = HandshakeParse(fields[2]); err != nil {
+			return ret, err
+		}
+	}
+	return ret, ret.Validate()
+}
+
+// String returns the settings in the compact notation, for example
+// "9600 8N1". The flow-control name is appended when flow control is used.
+// It satisfies fmt.Stringer.
+func (s SerialSettings) String() string {
+	var parity string
+	switch s.Parity {
+	case ParityNone:
+		parity = "N"
+	case ParityOdd:
+		parity = "O"
+	case ParityEven:
+		parity = "E"
+	case ParityMark:
+		parity = "M"
+	case ParitySpace:
+		parity = "S"
+	default:
+		parity = "?"
+	}
+	var stopBits string
+	switch s.StopBits {
+	case StopBitsOne:
+		stopBits = "1"
+	case StopBitsOnePointFive:
+		stopBits = "1.5"
+	case StopBitsTwo:
+		stopBits = "2"
+	default:
+		stopBits = "?"
+	}
+	ret := strconv.Itoa(int(s.BaudRate)) + " " + strconv.Itoa(int(s.DataBits)) + parity + stopBits
+	if s.Handshake != HandshakeNone {
+		ret += " " + s.Handshake.String()
+	}
+	return ret
+}
+
+// Validate checks that the settings are defined and that the combination of
+// data bits and stop bits is supported by UARTs: 1.5 stop bits is only
+// allowed with 5 data bits and 2 stop bits is not allowed with 5 data bits.
+//
+// It returns ErrArgumentOutOfRange if the baud rate or the number of data
+// bits is not valid, ErrUnknownEnum if an enum value is not defined and
+// ErrInvalidArgument if the combination is not valid.
+func (s SerialSettings) Validate() error {
+	if s.BaudRate <= 0 {
+		return ErrArgumentOutOfRangeError("BaudRate")
+	}
+	if s.DataBits.String() == "" {
+		return ErrArgumentOutOfRangeError("DataBits")
+	}
+	if s.Parity.String() == "" {
+		return ErrUnknownEnumError("Parity")
+	}
+	if s.StopBits.String() == "" {
+		return ErrUnknownEnumError("StopBits")
+	}
+	if s.Handshake.String() == "" {
+		return ErrUnknownEnumError("Handshake")
+	}
+	switch {
+	case s.StopBits == StopBitsNone:
+		return ErrInvalidArgumentError("StopBits")
+	case s.StopBits == StopBitsOnePointFive && s.DataBits != DataBits5,
+		s.StopBits == StopBitsTwo && s.DataBits == DataBits5:
+		return ErrInvalidArgumentError(fmt.Sprintf("%s stop bits with %d data bits", s.StopBits, s.DataBits))
+	}
+	return nil
+}
@@ -0,0 +1,144 @@
+package gxcommon
+
+// --------------------------------------------------------------------------
+//
+//	Gurux Ltd
+//
+// Filename:        $HeadURL$
+//
+// Version:         $Revision$,
+//
+//	$Date$
+//	$Author$
+//
+// # Copyright (c) Gurux Ltd
+//
+// ---------------------------------------------------------------------------
+//
+//	DESCRIPTION
+//
+// This file is a part of Gurux Device Framework.
+//
+// Gurux Device Framework is Open Source software; you can redistribute it
+// and/or modify it under the terms of the GNU General Public License
+// as published by the Free Software Foundation; version 2 of the License.
+// Gurux Device Framework is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.
+// See the GNU General Public License for more details.
+//
+// More information of Gurux products: https://www.gurux.org
+//
+// This code is licensed under the GNU General Public License v2.
+// Full text may be retrieved at http://www.gnu.org/licenses/gpl-2.0.txt
+// ---------------------------------------------------------------------------
+
+import (
+	"math"
+	"time"
+)
+
+const (
+	// highSpeedInterCharacterTimeout is the inter-character timeout of
+	// Modbus RTU above 19200 bps.
+	highSpeedInterCharacterTimeout = 750 * time.Microsecond
+	// highSpeedInterFrameTimeout is the inter-frame timeout of Modbus RTU
+	// above 19200 bps.
+	highSpeedInterFrameTimeout = 1750 * time.Microsecond
+)
+
+// BitsPerCharacter returns the number of bits used to transmit one
+// character: the start bit, the data bits, the parity bit and the stop bits.
+// The result is fractional with 1.5 stop bits.
+func BitsPerCharacter(dataBits DataBits, parity Parity, stopBits StopBits) float64 {
+	ret := 1 + float64(dataBits)
+	if parity != ParityNone {
+		ret++
+	}
+	switch stopBits {
+	case StopBitsOne:
+		ret++
+	case StopBitsOnePointFive:
+		ret += 1.5
+	case StopBitsTwo:
+		ret += 2
+	}
+	return ret
+}
+
+// CharacterTime returns the time to transmit one character.
+//
+// It returns zero if baudRate is not positive.
+func CharacterTime(baudRate BaudRate, dataBits DataBits, parity Parity, stopBits StopBits) time.Duration {
+	return TransmitTime(baudRate, dataBits, parity, stopBits, 1)
+}
+
+// TransmitTime returns the time to transmit count characters back to back.
+//
+// It returns zero if baudRate or count is not positive.
+func TransmitTime(baudRate BaudRate, dataBits DataBits, parity Parity, stopBits StopBits, count int) time.Duration {
+	if baudRate <= 0 || count <= 0 {
+		return 0
+	}
+	bits := BitsPerCharacter(dataBits, parity, stopBits) * float64(count)
+	return time.Duration(math.Ceil(bits * float64(time.Second) / float64(baudRate)))
+}
+
+// InterCharacterTimeout returns the longest allowed silence between the
+// characters of a frame. It is 1.5 character times (t1.5 of Modbus RTU), or
+// 750 µs above 19200 bps.
+//
+// It returns zero if baudRate is not positive.
+func InterCharacterTimeout(baudRate BaudRate, dataBits DataBits, parity Parity, stopBits StopBits) time.Duration {
+	if baudRate > BaudRate19200 {
+		return highSpeedInterCharacterTimeout
+	}
+	return CharacterTime(baudRate, dataBits, parity, stopBits) * 3 / 2
+}
+
+// InterFrameTimeout returns the silence that separates frames. It is 3.5
+// character times (t3.5 of Modbus RTU), or 1.75 ms above 19200 bps.
+//
+// It returns zero if baudRate is not positive.
+func InterFrameTimeout(baudRate BaudRate, dataBits DataBits, parity Parity, stopBits StopBits) time.Duration {
+	if baudRate > BaudRate19200 {
+		return highSpeedInterFrameTimeout
+	}
+	return CharacterTime(baudRate, dataBits, parity, stopBits) * 7 / 2
+}
+
+// WaitTimeOf converts d to the milliseconds of ReceiveParameters.WaitTime.
+// Partial milliseconds are rounded up so that a positive duration never
+// becomes zero. Negative durations are converted to -1 (infinite wait).
+func WaitTimeOf(d time.Duration) int {
+	if d < 0 {
+		return -1
+	}
+	return int((d + time.Millisecond - 1) / time.Millisecond)
+}
+
+// BitsPerCharacter returns the number of bits used to transmit one
+// character with s.
+func (s SerialSettings) BitsPerCharacter() float64 {
+	return BitsPerCharacter(s.DataBits, s.Parity, s.StopBits)
+}
+
+// CharacterTime returns the time to transmit one character with s.
+func (s SerialSettings) CharacterTime() time.Duration {
+	return CharacterTime(s.BaudRate, s.DataBits, s.Parity, s.StopBits)
+}
+
+// TransmitTime returns the time to transmit count characters with s.
+func (s SerialSettings) TransmitTime(count int) time.Duration {
+	return TransmitTime(s.BaudRate, s.DataBits, s.Parity, s.StopBits, count)
+}
+
+// InterCharacterTimeout returns the inter-character timeout of s.
+func (s SerialSettings) InterCharacterTimeout() time.Duration {
+	return InterCharacterTimeout(s.BaudRate, s.DataBits, s.Parity, s.StopBits)
+}
+
+// InterFrameTimeout returns the inter-frame timeout of s.
+func (s SerialSettings) InterFrameTimeout() time.Duration {
+	return InterFrameTimeout(s.BaudRate, s.DataBits, s.Parity, s.StopBits)
+}
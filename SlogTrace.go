@@ -0,0 +1,123 @@
+package gxcommon
+
+// --------------------------------------------------------------------------
+//
+//	Gurux Ltd
+//
+// Filename:        $HeadURL$
+//
+// Version:         $Revision$,
+//
+//	$Date$
+//	$Author$
+//
+// # Copyright (c) Gurux Ltd
+//
+// ---------------------------------------------------------------------------
+//
+//	DESCRIPTION
+//
+// This file is a part of Gurux Device Framework.
+//
+// Gurux Device Framework is Open Source software; you can redistribute it
+// and/or modify it under the terms of the GNU General Public License
+// as published by the Free Software Foundation; version 2 of the License.
+// Gurux Device Framework is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.
+// See the GNU General Public License for more details.
+//
+// More information of Gurux products: https://www.gurux.org
+//
+// This code is licensed under the GNU General Public License v2.
+// Full text may be retrieved at http://www.gnu.org/licenses/gpl-2.0.txt
+// ---------------------------------------------------------------------------
+
+import (
+	"context"
+	"log/slog"
+)
+
+// SlogLevel returns the slog level that corresponds to a trace level.
+//
+// TraceLevelVerbose maps to slog.LevelDebug. TraceLevelOff and undefined
+// levels map to slog.LevelError+4, a sentinel above every standard slog
+// level. Used as the minimum level of a slog handler, it disables all
+// records of the standard levels.
+func SlogLevel(level TraceLevel) slog.Level {
+	switch level {
+	case TraceLevelError:
+		return slog.LevelError
+	case TraceLevelWarning:
+		return slog.LevelWarn
+	case TraceLevelInfo:
+		return slog.LevelInfo
+	case TraceLevelVerbose:
+		return slog.LevelDebug
+	default:
+		return slog.LevelError + 4
+	}
+}
+
+// SlogTraceHandler returns a trace event handler that logs trace events
+// with logger.
+//
+// The record message is the trace type and the record has the attributes
+// media, mediaType, traceType, receiver, data and timestamp. Byte slice
+// payloads are logged as hex. Events that are not included in the current
+// trace level of the media are ignored.
+func SlogTraceHandler(logger *slog.Logger) TraceEventHandler {
+	return func(media IGXMedia, e TraceEventArgs) {
+		traceLevel := traceLevelOf(e.Type())
+		if media != nil && media.GetTrace() < traceLevel {
+			return
+		}
+		ctx := context.Background()
+		level := SlogLevel(traceLevel)
+		if !logger.Enabled(ctx, level) {
+			return
+		}
+		attrs := mediaAttrs(media)
+		attrs = append(attrs, slog.String("traceType", e.Type().String()))
+		if e.Receiver != "" {
+			attrs = append(attrs, slog.String("receiver", e.Receiver))
+		}
+		if e.Data() != nil {
+			data, _ := ToString(e.Data())
+			attrs = append(attrs, slog.String("data", data))
+		}
+		attrs = append(attrs, slog.Time("timestamp", e.Timestamp()))
+		logger.LogAttrs(ctx, level, e.Type().String(), attrs...)
+	}
+}
+
+// SlogErrorHandler returns an error event handler that logs media errors
+// with logger at slog.LevelError.
+//
+// The record has the attributes media, mediaType and error.
+func SlogErrorHandler(logger *slog.Logger) ErrorEventHandler {
+	return func(media IGXMedia, err error) {
+		attrs := mediaAttrs(media)
+		attrs = append(attrs, slog.Any("error", err))
+		logger.LogAttrs(context.Background(), slog.LevelError, "Media error", attrs...)
+	}
+}
+
+// AttachSlog logs trace events and errors of media with logger.
+//
+// It replaces the trace and error handlers of media.
+func AttachSlog(media IGXMedia, logger *slog.Logger) {
+	media.SetOnTrace(SlogTraceHandler(logger))
+	media.SetOnError(SlogErrorHandler(logger))
+}
+
+// mediaAttrs returns the log attributes that identify media.
+func mediaAttrs(media IGXMedia) []slog.Attr {
+	if media == nil {
+		return make([]slog.Attr, 0, 4)
+	}
+	ret := make([]slog.Attr, 0, 6)
+	return append(ret,
+		slog.String("media", media.GetName()),
+		slog.String("mediaType", media.GetMediaType()))
+}
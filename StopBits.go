@@ -35,6 +35,7 @@ package gxcommon
 
 import (
 	"fmt"
+	"strconv"
 	"strings"
 )
 
@@ -97,6 +98,27 @@ func (g StopBits) String() string {
 	return ret
 }
 
+// MarshalText returns the canonical stop-bit name.
+// It satisfies encoding.TextMarshaler.
+func (g StopBits) MarshalText() ([]byte, error) {
+	ret := g.String()
+	if ret == "" {
+		return nil, ErrUnknownEnumError(strconv.Itoa(int(g)))
+	}
+	return []byte(ret), nil
+}
+
+// UnmarshalText parses a stop-bit name with StopBitsParse.
+// It satisfies encoding.TextUnmarshaler.
+func (g *StopBits) UnmarshalText(text []byte) error {
+	ret, err := StopBitsParse(string(text))
+	if err != nil {
+		return err
+	}
+	*g = ret
+	return nil
+}
+
 // AllStopBits returns all defined StopBits values in declaration order.
 func AllStopBits() []StopBits {
 	return []StopBits{
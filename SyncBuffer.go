@@ -0,0 +1,346 @@
+package gxcommon
+
+// --------------------------------------------------------------------------
+//
+//	Gurux Ltd
+//
+// Filename:        $HeadURL$
+//
+// Version:         $Revision$,
+//
+//	$Date$
+//	$Author$
+//
+// # Copyright (c) Gurux Ltd
+//
+// ---------------------------------------------------------------------------
+//
+//	DESCRIPTION
+//
+// This file is a part of Gurux Device Framework.
+//
+// Gurux Device Framework is Open Source software; you can redistribute it
+// and/or modify it under the terms of the GNU General Public License
+// as published by the Free Software Foundation; version 2 of the License.
+// Gurux Device Framework is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.
+// See the GNU General Public License for more details.
+//
+// More information of Gurux products: https://www.gurux.org
+//
+// This code is licensed under the GNU General Public License v2.
+// Full text may be retrieved at http://www.gnu.org/licenses/gpl-2.0.txt
+// ---------------------------------------------------------------------------
+
+import (
+	"bytes"
+	"context"
+	"encoding/binary"
+	"sync"
+	"time"
+)
+
+// SyncBuffer is a receive buffer for synchronous communication.
+//
+// Data received from the connection is added with Append and read with
+// Receive, which interprets ReceiveParameters as follows:
+//
+//   - EOP is converted to bytes (a byte, a string, a byte slice or any value
+//     accepted by ToBytes) and the reply ends with the first occurrence of it.
+//   - Count is the number of bytes to read, or the number of elements if
+//     the reply type is a slice type such as DataTypeUint16Slice. If EOP is
+//     also set, the reply is at least Count bytes long and ends with EOP.
+//   - WaitTime is the maximum wait time in milliseconds. A negative value
+//     waits infinitely and zero returns immediately.
+//   - AllData moves all buffered data to Reply when the reply is found or
+//     when WaitTime elapses.
+//   - Framer, when set, is used instead of EOP, Count and AllData. The reply
+//     is the next frame returned by the framer and bytes that can not belong
+//     to a frame are discarded.
+//   - Peek returns the reply without removing it from the buffer.
+//   - EOP and the reply are converted with ByteOrder. If ByteOrder is nil,
+//     the byte order set with SetByteOrder is used, which is big-endian by
+//     default. The reply is converted with BytesToAny2.
+//     If ReplyType is DataTypeUnknown, the type is inferred from Reply and
+//     a byte slice is returned if Reply is nil.
+//
+// Receive waits on a condition variable and is woken up by Append, Close,
+// timeout and context cancellation. The zero value is an empty open
+// buffer. SyncBuffer is safe for concurrent use and must not be copied
+// after first use.
+type SyncBuffer struct {
+	mu     sync.Mutex
+	cond   *sync.Cond
+	data   []byte
+	closed bool
+	order  binary.ByteOrder
+}
+
+// NewSyncBuffer returns a new empty SyncBuffer.
+func NewSyncBuffer() *SyncBuffer {
+	return &SyncBuffer{}
+}
+
+// init creates the condition variable. The caller must hold s.mu.
+func (s *SyncBuffer) init() {
+	if s.cond == nil {
+		s.cond = sync.NewCond(&s.mu)
+	}
+}
+
+// Append adds received bytes to the buffer and wakes up waiting receivers.
+func (s *SyncBuffer) Append(data []byte) {
+	if len(data) == 0 {
+		return
+	}
+	s.mu.Lock()
+	s.init()
+	s.data = append(s.data, data...)
+	s.cond.Broadcast()
+	s.mu.Unlock()
+}
+
+// ByteOrder returns the default byte order of the replies.
+func (s *SyncBuffer) ByteOrder() binary.ByteOrder {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.byteOrder()
+}
+
+// SetByteOrder sets the default byte order of the replies. It is used when
+// ReceiveParameters.ByteOrder is nil. A nil value restores big-endian.
+func (s *SyncBuffer) SetByteOrder(value binary.ByteOrder) {
+	s.mu.Lock()
+	s.order = value
+	s.mu.Unlock()
+}
+
+// byteOrder returns the default byte order. The caller must hold s.mu.
+func (s *SyncBuffer) byteOrder() binary.ByteOrder {
+	if s.order == nil {
+		return binary.BigEndian
+	}
+	return s.order
+}
+
+// Len returns the number of buffered bytes.
+func (s *SyncBuffer) Len() int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return len(s.data)
+}
+
+// Clear removes all buffered bytes.
+func (s *SyncBuffer) Clear() {
+	s.mu.Lock()
+	s.data = s.data[:0]
+	s.mu.Unlock()
+}
+
+// Reset clears the buffer and allows receiving again after Close.
+func (s *SyncBuffer) Reset() {
+	s.mu.Lock()
+	s.data = s.data[:0]
+	s.closed = false
+	s.mu.Unlock()
+}
+
+// Close wakes up all waiting receivers. Receive returns ErrConnectionClosed
+// until the buffer is reset.
+func (s *SyncBuffer) Close() {
+	s.mu.Lock()
+	s.init()
+	s.closed = true
+	s.cond.Broadcast()
+	s.mu.Unlock()
+}
+
+// Receive waits until the reply described by args is available and stores
+// it to args.Reply.
+//
+// The returned bool reports whether the reply was found before WaitTime
+// elapsed. If AllData is set, Reply is updated also on timeout when there is
+// buffered data. It returns ErrInvalidArgument if neither Count, EOP,
+// AllData nor Framer is set and ErrConnectionClosed if the buffer is closed.
+// An error returned by Framer is returned after the malformed frame has been
+// removed from the buffer.
+func (s *SyncBuffer) Receive(args *ReceiveParameters) (bool, error) {
+	return s.ReceiveContext(context.Background(), args)
+}
+
+// ReceiveContext is like Receive but it also stops waiting when ctx is
+// done. In that case it returns ctx.Err() and the buffer is left untouched.
+func (s *SyncBuffer) ReceiveContext(ctx context.Context, args *ReceiveParameters) (bool, error) {
+	if args == nil {
+		return false, ErrInvalidArgumentError("args")
+	}
+	order := args.ByteOrder
+	if order == nil {
+		order = s.ByteOrder()
+	}
+	var eop []byte
+	if args.EOP != nil {
+		var err error
+		if eop, err = eopBytes(args.EOP, order); err != nil {
+			return false, err
+		}
+	}
+	if args.Framer == nil && len(eop) == 0 && args.Count <= 0 && !args.AllData {
+		return false, ErrInvalidArgumentError("Count or EOP must be set")
+	}
+	t := replyType(args)
+	count := byteCount(args.Count, t)
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.init()
+	timeout := false
+	if args.WaitTime > 0 {
+		timer := time.AfterFunc(time.Duration(args.WaitTime)*time.Millisecond, func() {
+			s.mu.Lock()
+			timeout = true
+			s.cond.Broadcast()
+			s.mu.Unlock()
+		})
+		defer timer.Stop()
+	}
+	if ctx.Done() != nil {
+		stop := context.AfterFunc(ctx, func() {
+			s.mu.Lock()
+			s.cond.Broadcast()
+			s.mu.Unlock()
+		})
+		defer stop()
+	}
+	var reply []byte
+	found := -1
+	for {
+		if s.closed {
+			return false, ErrConnectionClosed
+		}
+		if err := ctx.Err(); err != nil {
+			return false, err
+		}
+		if args.Framer != nil {
+			frame, n, err := s.frame(args.Framer, args.Peek)
+			if err != nil {
+				if !args.Peek {
+					s.data = s.data[:copy(s.data, s.data[n:])]
+				}
+				return false, err
+			}
+			if frame != nil {
+				reply, found = frame, n
+			}
+		} else if found = s.find(eop, count); found != -1 {
+			reply = s.data[:found]
+		}
+		if found != -1 || timeout || args.WaitTime == 0 {
+			break
+		}
+		s.cond.Wait()
+	}
+	ret := true
+	if found == -1 {
+		if args.Framer != nil || !args.AllData || len(s.data) == 0 {
+			return false, nil
+		}
+		ret = false
+	}
+	if args.AllData && args.Framer == nil {
+		found = len(s.data)
+		reply = s.data
+	}
+	value, err := BytesToAny2(reply, t, order)
+	if err != nil {
+		return false, err
+	}
+	if !args.Peek {
+		s.data = s.data[:copy(s.data, s.data[found:])]
+	}
+	args.Reply = value
+	return ret, nil
+}
+
+// find returns the length of the reply in the buffer or -1 if the reply is
+// not complete yet. The caller must hold s.mu.
+func (s *SyncBuffer) find(eop []byte, count int) int {
+	if len(s.data) == 0 || len(s.data) < count || len(s.data) < len(eop) {
+		return -1
+	}
+	if len(eop) == 0 {
+		if count <= 0 {
+			return len(s.data)
+		}
+		return count
+	}
+	// The reply must be at least count bytes long and end with EOP.
+	start := max(count-len(eop), 0)
+	pos := bytes.Index(s.data[start:], eop)
+	if pos == -1 {
+		return -1
+	}
+	return start + pos + len(eop)
+}
+
+// frame returns the first frame in the buffer and the number of bytes up to
+// the end of it. Bytes that can not belong to a frame are removed from the
+// buffer unless peek is set. If the frame is malformed, the returned length
+// is the number of bytes to discard. The caller must hold s.mu.
+func (s *SyncBuffer) frame(framer Framer, peek bool) ([]byte, int, error) {
+	pos := 0
+	for {
+		frame, n, err := framer.Next(s.data[pos:])
+		if err != nil || frame != nil {
+			return frame, pos + n, err
+		}
+		if n == 0 {
+			return nil, -1, nil
+		}
+		if peek {
+			pos += n
+		} else {
+			s.data = s.data[:copy(s.data, s.data[n:])]
+		}
+	}
+}
+
+// eopBytes converts an end-of-packet marker to bytes.
+func eopBytes(eop any, order binary.ByteOrder) ([]byte, error) {
+	switch x := eop.(type) {
+	case []byte:
+		return x, nil
+	case byte:
+		return []byte{x}, nil
+	case string:
+		return []byte(x), nil
+	default:
+		return ToBytes(eop, order)
+	}
+}
+
+// replyType returns the reply type of args.
+func replyType(args *ReceiveParameters) DataType {
+	if args.ReplyType == DataTypeUnknown {
+		return dataTypeOf(args.Reply)
+	}
+	return args.ReplyType
+}
+
+// byteCount converts count to bytes. count is the number of elements for
+// slice types and the number of bytes for other types.
+func byteCount(count int, t DataType) int {
+	if t.isSlice() {
+		return count * t.ElementSize()
+	}
+	return count
+}
+
+// dataTypeOf returns the DataType of value or DataTypeBytes if the type is
+// not supported.
+func dataTypeOf(value any) DataType {
+	if ret := typeOf(value); ret != DataTypeUnknown {
+		return ret
+	}
+	return DataTypeBytes
+}
@@ -77,6 +77,18 @@ func NewTraceEventArgs(traceType TraceTypes, data any, receiver string) *TraceEv
 	}
 }
 
+// NewTraceEventArgsAt creates a TraceEventArgs like NewTraceEventArgs, but
+// with the given timestamp. It is used when trace events are restored, for
+// example from a capture file.
+func NewTraceEventArgsAt(timestamp time.Time, traceType TraceTypes, data any, receiver string) *TraceEventArgs {
+	return &TraceEventArgs{
+		timestamp: timestamp,
+		traceType: traceType,
+		data:      data,
+		Receiver:  receiver,
+	}
+}
+
 // String returns a tab-separated string with timestamp, trace type, and data.
 func (e *TraceEventArgs) String() string {
 	str, _ := ToString(e.data)
@@ -0,0 +1,122 @@
+package checksum
+
+// --------------------------------------------------------------------------
+//
+//	Gurux Ltd
+//
+// Filename:        $HeadURL$
+//
+// Version:         $Revision$,
+//
+//	$Date$
+//	$Author$
+//
+// # Copyright (c) Gurux Ltd
+//
+// ---------------------------------------------------------------------------
+//
+//	DESCRIPTION
+//
+// This file is a part of Gurux Device Framework.
+//
+// Gurux Device Framework is Open Source software; you can redistribute it
+// and/or modify it under the terms of the GNU General Public License
+// as published by the Free Software Foundation; version 2 of the License.
+// Gurux Device Framework is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.
+// See the GNU General Public License for more details.
+//
+// More information of Gurux products: https://www.gurux.org
+//
+// This code is licensed under the GNU General Public License v2.
+// Full text may be retrieved at http://www.gnu.org/licenses/gpl-2.0.txt
+// ---------------------------------------------------------------------------
+
+import (
+	"encoding/binary"
+	"hash"
+	"hash/crc32"
+
+	"github.com/Gurux/gxcommon-go"
+)
+
+// Hash16 is the common interface implemented by all 16-bit checksums.
+type Hash16 interface {
+	hash.Hash
+	Sum16() uint16
+}
+
+// Hash8 is the common interface implemented by all 8-bit checksums.
+type Hash8 interface {
+	hash.Hash
+	Sum8() uint8
+}
+
+// NewCRC32 returns a new hash.Hash32 computing the CRC-32 (IEEE) checksum.
+func NewCRC32() hash.Hash32 {
+	return crc32.NewIEEE()
+}
+
+// CRC32 returns the CRC-32 (IEEE) checksum of data.
+func CRC32(data []byte) uint32 {
+	return crc32.ChecksumIEEE(data)
+}
+
+// sum returns the checksum of h as an unsigned integer of h.Size() bytes.
+func sum(h hash.Hash) (any, error) {
+	switch x := h.(type) {
+	case Hash8:
+		return x.Sum8(), nil
+	case Hash16:
+		return x.Sum16(), nil
+	case hash.Hash32:
+		return x.Sum32(), nil
+	case hash.Hash64:
+		return x.Sum64(), nil
+	default:
+		return nil, gxcommon.ErrInvalidArgumentError("h")
+	}
+}
+
+// Append computes the checksum of data with h and appends it to data in the
+// given byte order.
+//
+// h is reset before use. It returns ErrInvalidArgument if h is not an 8, 16,
+// 32 or 64-bit checksum.
+func Append(data []byte, h hash.Hash, order binary.ByteOrder) ([]byte, error) {
+	h.Reset()
+	h.Write(data)
+	value, err := sum(h)
+	if err != nil {
+		return nil, err
+	}
+	b, err := gxcommon.ToBytes(value, order)
+	if err != nil {
+		return nil, err
+	}
+	return append(data, b...), nil
+}
+
+// Verify reports whether data ends with the checksum of the bytes before it
+// in the given byte order.
+//
+// h is reset before use. It returns ErrInvalidArgument if h is not an 8, 16,
+// 32 or 64-bit checksum.
+func Verify(data []byte, h hash.Hash, order binary.ByteOrder) (bool, error) {
+	size := h.Size()
+	if len(data) < size {
+		return false, nil
+	}
+	h.Reset()
+	h.Write(data[:len(data)-size])
+	value, err := sum(h)
+	if err != nil {
+		return false, err
+	}
+	b, err := gxcommon.ToBytes(value, order)
+	if err != nil {
+		return false, err
+	}
+	return string(b) == string(data[len(data)-size:]), nil
+}
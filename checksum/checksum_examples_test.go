@@ -0,0 +1,22 @@
+// Package checksum_test holds examples for the checksum package.
+package checksum_test
+
+import (
+	"encoding/binary"
+	"fmt"
+
+	"github.com/Gurux/gxcommon-go"
+	"github.com/Gurux/gxcommon-go/checksum"
+)
+
+// ExampleAppend adds the CRC of a Modbus RTU request and verifies it.
+func ExampleAppend() {
+	// Read holding registers 0 and 1 of slave 1.
+	data, _ := checksum.Append([]byte{0x01, 0x03, 0x00, 0x00, 0x00, 0x02}, checksum.NewModbus(), binary.LittleEndian)
+	fmt.Println(gxcommon.ToHex(data))
+	ok, _ := checksum.Verify(data, checksum.NewModbus(), binary.LittleEndian)
+	fmt.Println(ok)
+	// Output:
+	// 01 03 00 00 00 02 C4 0B
+	// true
+}
@@ -0,0 +1,82 @@
+package checksum_test
+
+import (
+	"encoding/binary"
+	"hash"
+	"testing"
+
+	"github.com/Gurux/gxcommon-go/checksum"
+)
+
+// check is the standard check input of the CRC catalogue.
+var check = []byte("123456789")
+
+func TestCheckValues(t *testing.T) {
+	tests := []struct {
+		name string
+		h    hash.Hash
+		want []byte
+	}{
+		{"X25", checksum.NewX25(), []byte{0x90, 0x6E}},
+		{"Modbus", checksum.NewModbus(), []byte{0x4B, 0x37}},
+		{"CCITTFalse", checksum.NewCCITTFalse(), []byte{0x29, 0xB1}},
+		{"EN13757", checksum.NewEN13757(), []byte{0xC2, 0xB7}},
+		{"CRC32", checksum.NewCRC32(), []byte{0xCB, 0xF4, 0x39, 0x26}},
+		{"LRC", checksum.NewLRC(), []byte{0x23}},
+		{"XOR", checksum.NewXOR(), []byte{0x31}},
+		{"Sum8", checksum.NewSum8(), []byte{0xDD}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Write in parts to check streaming.
+			tt.h.Write(check[:4])
+			tt.h.Write(check[4:])
+			if got := tt.h.Sum(nil); string(got) != string(tt.want) {
+				t.Errorf("Sum = % X, want % X", got, tt.want)
+			}
+			tt.h.Reset()
+			tt.h.Write(check)
+			if got := tt.h.Sum(nil); string(got) != string(tt.want) {
+				t.Errorf("Sum after Reset = % X, want % X", got, tt.want)
+			}
+			data, err := checksum.Append([]byte("123456789"), tt.h, binary.BigEndian)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if ok, err := checksum.Verify(data, tt.h, binary.BigEndian); !ok || err != nil {
+				t.Errorf("Verify = %v, %v", ok, err)
+			}
+			data[0] ^= 1
+			if ok, _ := checksum.Verify(data, tt.h, binary.BigEndian); ok {
+				t.Error("Verify accepted corrupted data")
+			}
+		})
+	}
+}
+
+func TestFunctions(t *testing.T) {
+	if got := checksum.X25(check); got != 0x906E {
+		t.Errorf("X25 = %04X", got)
+	}
+	if got := checksum.Modbus(check); got != 0x4B37 {
+		t.Errorf("Modbus = %04X", got)
+	}
+	if got := checksum.CCITTFalse(check); got != 0x29B1 {
+		t.Errorf("CCITTFalse = %04X", got)
+	}
+	if got := checksum.EN13757(check); got != 0xC2B7 {
+		t.Errorf("EN13757 = %04X", got)
+	}
+	if got := checksum.CRC32(check); got != 0xCBF43926 {
+		t.Errorf("CRC32 = %08X", got)
+	}
+	if got := checksum.LRC(check); got != 0x23 {
+		t.Errorf("LRC = %02X", got)
+	}
+	if got := checksum.XOR(check); got != 0x31 {
+		t.Errorf("XOR = %02X", got)
+	}
+	if got := checksum.Sum8(check); got != 0xDD {
+		t.Errorf("Sum8 = %02X", got)
+	}
+}
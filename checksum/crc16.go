@@ -0,0 +1,188 @@
+package checksum
+
+// --------------------------------------------------------------------------
+//
+//	Gurux Ltd
+//
+// Filename:        $HeadURL$
+//
+// Version:         $Revision$,
+//
+//	$Date$
+//	$Author$
+//
+// # Copyright (c) Gurux Ltd
+//
+// ---------------------------------------------------------------------------
+//
+//	DESCRIPTION
+//
+// This file is a part of Gurux Device Framework.
+//
+// Gurux Device Framework is Open Source software; you can redistribute it
+// and/or modify it under the terms of the GNU General Public License
+// as published by the Free Software Foundation; version 2 of the License.
+// Gurux Device Framework is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.
+// See the GNU General Public License for more details.
+//
+// More information of Gurux products: https://www.gurux.org
+//
+// This code is licensed under the GNU General Public License v2.
+// Full text may be retrieved at http://www.gnu.org/licenses/gpl-2.0.txt
+// ---------------------------------------------------------------------------
+
+import "math/bits"
+
+// crc16Params are the parameters of a CRC-16 algorithm.
+type crc16Params struct {
+	table     *[256]uint16
+	init      uint16
+	xorOut    uint16
+	reflected bool
+}
+
+var (
+	x25        = crc16Params{table: makeTable16(0x1021, true), init: 0xFFFF, xorOut: 0xFFFF, reflected: true}
+	modbus     = crc16Params{table: makeTable16(0x8005, true), init: 0xFFFF, reflected: true}
+	ccittFalse = crc16Params{table: makeTable16(0x1021, false), init: 0xFFFF}
+	en13757    = crc16Params{table: makeTable16(0x3D65, false), xorOut: 0xFFFF}
+)
+
+// makeTable16 returns the lookup table of a CRC-16 polynomial. A reflected
+// table processes the least significant bit first.
+func makeTable16(poly uint16, reflected bool) *[256]uint16 {
+	ret := new([256]uint16)
+	if reflected {
+		poly = bits.Reverse16(poly)
+	}
+	for i := range ret {
+		var crc uint16
+		if reflected {
+			crc = uint16(i)
+			for range 8 {
+				if crc&1 != 0 {
+					crc = crc>>1 ^ poly
+				} else {
+					crc >>= 1
+				}
+			}
+		} else {
+			crc = uint16(i) << 8
+			for range 8 {
+				if crc&0x8000 != 0 {
+					crc = crc<<1 ^ poly
+				} else {
+					crc <<= 1
+				}
+			}
+		}
+		ret[i] = crc
+	}
+	return ret
+}
+
+// crc16 is a table-driven CRC-16.
+type crc16 struct {
+	params *crc16Params
+	crc    uint16
+}
+
+// NewX25 returns a new Hash16 computing CRC-16/X.25, the frame check
+// sequence of HDLC. The FCS is sent in little-endian byte order.
+func NewX25() Hash16 {
+	return newCRC16(&x25)
+}
+
+// NewModbus returns a new Hash16 computing CRC-16/Modbus. The CRC is sent
+// in little-endian byte order.
+func NewModbus() Hash16 {
+	return newCRC16(&modbus)
+}
+
+// NewCCITTFalse returns a new Hash16 computing CRC-16/CCITT-FALSE.
+func NewCCITTFalse() Hash16 {
+	return newCRC16(&ccittFalse)
+}
+
+// NewEN13757 returns a new Hash16 computing CRC-16/EN-13757, the CRC of
+// wireless M-Bus. The CRC is sent in big-endian byte order.
+func NewEN13757() Hash16 {
+	return newCRC16(&en13757)
+}
+
+// X25 returns the CRC-16/X.25 checksum of data.
+func X25(data []byte) uint16 {
+	return checksum16(&x25, data)
+}
+
+// Modbus returns the CRC-16/Modbus checksum of data.
+func Modbus(data []byte) uint16 {
+	return checksum16(&modbus, data)
+}
+
+// CCITTFalse returns the CRC-16/CCITT-FALSE checksum of data.
+func CCITTFalse(data []byte) uint16 {
+	return checksum16(&ccittFalse, data)
+}
+
+// EN13757 returns the CRC-16/EN-13757 checksum of data.
+func EN13757(data []byte) uint16 {
+	return checksum16(&en13757, data)
+}
+
+// checksum16 returns the CRC-16 of data.
+func checksum16(params *crc16Params, data []byte) uint16 {
+	h := crc16{params: params, crc: params.init}
+	h.Write(data)
+	return h.Sum16()
+}
+
+// newCRC16 returns a new CRC-16 hash.
+func newCRC16(params *crc16Params) *crc16 {
+	return &crc16{params: params, crc: params.init}
+}
+
+// Write adds data to the running checksum. It never returns an error.
+func (h *crc16) Write(data []byte) (int, error) {
+	crc := h.crc
+	table := h.params.table
+	if h.params.reflected {
+		for _, it := range data {
+			crc = table[byte(crc)^it] ^ crc>>8
+		}
+	} else {
+		for _, it := range data {
+			crc = table[byte(crc>>8)^it] ^ crc<<8
+		}
+	}
+	h.crc = crc
+	return len(data), nil
+}
+
+// Sum16 returns the checksum.
+func (h *crc16) Sum16() uint16 {
+	return h.crc ^ h.params.xorOut
+}
+
+// Sum appends the checksum to b in big-endian byte order.
+func (h *crc16) Sum(b []byte) []byte {
+	s := h.Sum16()
+	return append(b, byte(s>>8), byte(s))
+}
+
+// Reset resets the hash to its initial state.
+func (h *crc16) Reset() {
+	h.crc = h.params.init
+}
+
+// Size returns 2.
+func (h *crc16) Size() int {
+	return 2
+}
+
+// BlockSize returns 1.
+func (h *crc16) BlockSize() int {
+	return 1
+}
@@ -0,0 +1,130 @@
+package checksum
+
+// --------------------------------------------------------------------------
+//
+//	Gurux Ltd
+//
+// Filename:        $HeadURL$
+//
+// Version:         $Revision$,
+//
+//	$Date$
+//	$Author$
+//
+// # Copyright (c) Gurux Ltd
+//
+// ---------------------------------------------------------------------------
+//
+//	DESCRIPTION
+//
+// This file is a part of Gurux Device Framework.
+//
+// Gurux Device Framework is Open Source software; you can redistribute it
+// and/or modify it under the terms of the GNU General Public License
+// as published by the Free Software Foundation; version 2 of the License.
+// Gurux Device Framework is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.
+// See the GNU General Public License for more details.
+//
+// More information of Gurux products: https://www.gurux.org
+//
+// This code is licensed under the GNU General Public License v2.
+// Full text may be retrieved at http://www.gnu.org/licenses/gpl-2.0.txt
+// ---------------------------------------------------------------------------
+
+// sum8Kind tells how an 8-bit checksum is computed.
+type sum8Kind int
+
+const (
+	// kindSum is the sum of the bytes.
+	kindSum sum8Kind = iota
+	// kindLRC is the two's complement of the sum of the bytes.
+	kindLRC
+	// kindXOR is the XOR of the bytes.
+	kindXOR
+)
+
+// sum8 is an 8-bit checksum.
+type sum8 struct {
+	kind  sum8Kind
+	value byte
+}
+
+// NewLRC returns a new Hash8 computing the longitudinal redundancy check
+// of Modbus ASCII, the two's complement of the sum of the bytes.
+func NewLRC() Hash8 {
+	return &sum8{kind: kindLRC}
+}
+
+// NewXOR returns a new Hash8 computing the XOR of the bytes, the block
+// check character (BCC) of IEC 62056-21.
+func NewXOR() Hash8 {
+	return &sum8{kind: kindXOR}
+}
+
+// NewSum8 returns a new Hash8 computing the sum of the bytes modulo 256.
+func NewSum8() Hash8 {
+	return &sum8{kind: kindSum}
+}
+
+// LRC returns the longitudinal redundancy check of data.
+func LRC(data []byte) uint8 {
+	return -Sum8(data)
+}
+
+// XOR returns the XOR of data.
+func XOR(data []byte) uint8 {
+	h := sum8{kind: kindXOR}
+	h.Write(data)
+	return h.value
+}
+
+// Sum8 returns the sum of data modulo 256.
+func Sum8(data []byte) uint8 {
+	h := sum8{kind: kindSum}
+	h.Write(data)
+	return h.value
+}
+
+// Write adds data to the running checksum. It never returns an error.
+func (h *sum8) Write(data []byte) (int, error) {
+	if h.kind == kindXOR {
+		for _, it := range data {
+			h.value ^= it
+		}
+	} else {
+		for _, it := range data {
+			h.value += it
+		}
+	}
+	return len(data), nil
+}
+
+// Sum8 returns the checksum.
+func (h *sum8) Sum8() uint8 {
+	if h.kind == kindLRC {
+		return -h.value
+	}
+	return h.value
+}
+
+// Sum appends the checksum to b.
+func (h *sum8) Sum(b []byte) []byte {
+	return append(b, h.Sum8())
+}
+
+// Reset resets the hash to its initial state.
+func (h *sum8) Reset() {
+	h.value = 0
+}
+
+// Size returns 1.
+func (h *sum8) Size() int {
+	return 1
+}
+
+// BlockSize returns 1.
+func (h *sum8) BlockSize() int {
+	return 1
+}
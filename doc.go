@@ -5,12 +5,32 @@
 //
 //   - the IGXMedia interface and associated event argument types used by all
 //     media implementations (serial, TCP, USB, etc.)
-//   - common serial‑port enums (BaudRate, Parity, StopBits) with parsing and
-//     String helpers
+//   - GXMediaBase, an embeddable implementation of the media independent
+//     part of IGXMedia (events, counters, tracing and synchronous receive),
+//     and GXMediaWrapper, a base for medias that wrap another media
+//   - SyncBuffer, which implements the ReceiveParameters semantics, and
+//     IGXMediaContext for context-aware open, send and receive
+//   - the Framer interface for frame boundary detection with EOP,
+//     length-prefix, STX/ETX, SLIP and HDLC framers
+//   - a media registry (RegisterMedia, NewMedia, MediaTypes) for creating
+//     medias from a media type and a settings string, and MediaURI for
+//     describing connections as URIs
+//   - common serial‑port enums (BaudRate, DataBits, Parity, StopBits,
+//     Handshake) with parsing and String helpers, and SerialSettings, which
+//     combines them and parses notations such as "9600,7E1", with serial
+//     line timing helpers (CharacterTime, TransmitTime, InterFrameTimeout)
 //   - tracing and state enums (TraceLevel, TraceTypes, MediaState) plus
-//     utilities for working with them
-//   - data conversion helpers (ToBytes, ToString, GetType) and a small set of
-//     errors used throughout the framework
+//     utilities for working with them, including a log/slog bridge
+//     (AttachSlog)
+//   - data conversion helpers (ToBytes, BytesToAny, ToString, GetType) for
+//     numbers, strings and slices of numbers, Marshal and Unmarshal for
+//     struct tag driven binary records, GXByteBuffer for positional
+//     encoding and decoding, word-swapped byte orders (WordSwappedBigEndian,
+//     WordSwappedLittleEndian) for devices that store 32-bit and 64-bit
+//     values in 16-bit words, BCD and packed decimal codecs (AppendBCD,
+//     ParseBCD, AppendPackedDecimal, ParsePackedDecimal), BitReader and
+//     BitWriter for bit-packed fields, and a small set of errors used
+//     throughout the framework
 //   - simple language subscription helpers used for localized messages
 //
 // The package is documented with examples so that `go doc` or `pkg.go.dev` can
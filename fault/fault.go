@@ -0,0 +1,359 @@
+package fault
+
+// --------------------------------------------------------------------------
+//
+//	Gurux Ltd
+//
+// Filename:        $HeadURL$
+//
+// Version:         $Revision$,
+//
+//	$Date$
+//	$Author$
+//
+// # Copyright (c) Gurux Ltd
+//
+// ---------------------------------------------------------------------------
+//
+//	DESCRIPTION
+//
+// This file is a part of Gurux Device Framework.
+//
+// Gurux Device Framework is Open Source software; you can redistribute it
+// and/or modify it under the terms of the GNU General Public License
+// as published by the Free Software Foundation; version 2 of the License.
+// Gurux Device Framework is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.
+// See the GNU General Public License for more details.
+//
+// More information of Gurux products: https://www.gurux.org
+//
+// This code is licensed under the GNU General Public License v2.
+// Full text may be retrieved at http://www.gnu.org/licenses/gpl-2.0.txt
+// ---------------------------------------------------------------------------
+
+import (
+	"bytes"
+	"fmt"
+	"math/rand/v2"
+	"sync"
+	"time"
+
+	"github.com/Gurux/gxcommon-go"
+)
+
+// Fault is an injected fault.
+type Fault int
+
+const (
+	// FaultNone passes data unchanged.
+	FaultNone Fault = iota
+	// FaultDrop discards the data.
+	FaultDrop
+	// FaultDelay delivers the data after the configured delay.
+	FaultDelay
+	// FaultDuplicate delivers the data twice.
+	FaultDuplicate
+	// FaultFragment splits the data into two parts.
+	FaultFragment
+	// FaultReorder delivers the data after the next data.
+	FaultReorder
+	// FaultCorrupt inverts one random bit of the data.
+	FaultCorrupt
+)
+
+// String returns the fault name.
+// It satisfies fmt.Stringer.
+func (g Fault) String() string {
+	var ret string
+	switch g {
+	case FaultNone:
+		ret = "None"
+	case FaultDrop:
+		ret = "Drop"
+	case FaultDelay:
+		ret = "Delay"
+	case FaultDuplicate:
+		ret = "Duplicate"
+	case FaultFragment:
+		ret = "Fragment"
+	case FaultReorder:
+		ret = "Reorder"
+	case FaultCorrupt:
+		ret = "Corrupt"
+	}
+	return ret
+}
+
+// Direction tells whether a fault is injected to sent or received data.
+type Direction int
+
+const (
+	// DirectionSend is data sent with Send.
+	DirectionSend Direction = iota
+	// DirectionReceive is data received from the inner media.
+	DirectionReceive
+)
+
+// String returns the direction name.
+// It satisfies fmt.Stringer.
+func (g Direction) String() string {
+	var ret string
+	switch g {
+	case DirectionSend:
+		ret = "Send"
+	case DirectionReceive:
+		ret = "Receive"
+	}
+	return ret
+}
+
+// Probabilities are the probabilities of faults in one direction. Each
+// value is between 0 and 1. At most one fault is injected to each data and
+// the faults are tried in field order.
+type Probabilities struct {
+	Drop      float64
+	Delay     float64
+	Duplicate float64
+	Fragment  float64
+	Reorder   float64
+	Corrupt   float64
+}
+
+// Step is a scripted fault.
+type Step struct {
+	// Direction is the direction of the data.
+	Direction Direction
+	// Index is the 1-based number of the data in Direction, counted from
+	// the creation of the media.
+	Index int
+	// Fault is the injected fault.
+	Fault Fault
+}
+
+// chunk is data that is delivered after delay.
+type chunk struct {
+	data  []byte
+	delay time.Duration
+}
+
+// Media is an IGXMedia that injects faults to the data of an inner media.
+//
+// Every Send call and every received event of the inner media is one data.
+// A scripted Step for the data takes precedence over the probabilities.
+// Each injected fault is reported as a TraceTypesWarning trace event.
+type Media struct {
+	gxcommon.GXMediaWrapper
+	mu            sync.Mutex
+	rand          *rand.Rand
+	probabilities [2]Probabilities
+	delay         time.Duration
+	schedule      []Step
+	counts        [2]int
+	held          [2][]byte
+	// closed is closed when the media is closed to stop delayed sends.
+	closed chan struct{}
+	// generation is incremented when the media is closed so that delayed
+	// received data is discarded.
+	generation int
+}
+
+// New returns a Media that injects faults to the data of inner. No faults
+// are injected until probabilities or a schedule are set.
+//
+// The received, error and media state handlers of inner are replaced. Use
+// the handlers of the returned Media instead.
+func New(inner gxcommon.IGXMedia) *Media {
+	ret := &Media{
+		rand:  rand.New(rand.NewPCG(uint64(time.Now().UnixNano()), 0)),
+		delay: 100 * time.Millisecond,
+	}
+	ret.InitWrapper(ret, inner)
+	return ret
+}
+
+// SetSeed sets the seed of the random generator so that random faults can
+// be reproduced.
+func (m *Media) SetSeed(seed uint64) {
+	m.mu.Lock()
+	m.rand = rand.New(rand.NewPCG(seed, 0))
+	m.mu.Unlock()
+}
+
+// SetProbabilities sets the fault probabilities of a direction.
+func (m *Media) SetProbabilities(direction Direction, value Probabilities) {
+	m.mu.Lock()
+	m.probabilities[direction] = value
+	m.mu.Unlock()
+}
+
+// SetDelay sets the delay of FaultDelay. The default is 100 ms.
+func (m *Media) SetDelay(value time.Duration) {
+	m.mu.Lock()
+	m.delay = value
+	m.mu.Unlock()
+}
+
+// SetSchedule sets the scripted faults.
+func (m *Media) SetSchedule(steps ...Step) {
+	m.mu.Lock()
+	m.schedule = steps
+	m.mu.Unlock()
+}
+
+// Send injects faults to data and sends the result with the inner media.
+//
+// A delayed send blocks until the delay has elapsed. If the media is closed
+// during the delay, the data is discarded and Send returns
+// ErrConnectionClosed. The sent byte counter and trace contain data as
+// given.
+func (m *Media) Send(data any, receiver string) error {
+	b, err := gxcommon.ToBytes(data, m.GetByteOrder())
+	if err != nil {
+		return err
+	}
+	if !m.IsOpen() {
+		return gxcommon.ErrConnectionClosed
+	}
+	for _, it := range m.inject(DirectionSend, b) {
+		if it.delay != 0 && !m.wait(it.delay) {
+			return gxcommon.ErrConnectionClosed
+		}
+		if err = m.Inner().Send(it.data, receiver); err != nil {
+			return err
+		}
+	}
+	m.NotifySent(m, b, receiver)
+	return nil
+}
+
+// NotifyReceived injects faults to the data received from the inner media.
+// Delayed data is discarded if the media is closed during the delay.
+func (m *Media) NotifyReceived(media gxcommon.IGXMedia, data []byte, sender string) {
+	m.mu.Lock()
+	generation := m.generation
+	m.mu.Unlock()
+	for _, it := range m.inject(DirectionReceive, data) {
+		if it.delay == 0 {
+			m.GXMediaWrapper.NotifyReceived(media, it.data, sender)
+		} else {
+			time.AfterFunc(it.delay, func() {
+				m.mu.Lock()
+				ok := m.generation == generation
+				m.mu.Unlock()
+				if ok {
+					m.GXMediaWrapper.NotifyReceived(media, it.data, sender)
+				}
+			})
+		}
+	}
+}
+
+// wait waits for delay. It returns false if the media is closed before the
+// delay has elapsed.
+func (m *Media) wait(delay time.Duration) bool {
+	m.mu.Lock()
+	if m.closed == nil {
+		m.closed = make(chan struct{})
+	}
+	closed := m.closed
+	m.mu.Unlock()
+	t := time.NewTimer(delay)
+	defer t.Stop()
+	select {
+	case <-t.C:
+		return true
+	case <-closed:
+		return false
+	}
+}
+
+// NotifyMediaStateChange discards reordered and delayed data and stops
+// delayed sends when the media is closed.
+func (m *Media) NotifyMediaStateChange(media gxcommon.IGXMedia, state gxcommon.MediaState) {
+	if state == gxcommon.MediaStateClosed {
+		m.mu.Lock()
+		m.held = [2][]byte{}
+		m.generation++
+		if m.closed != nil {
+			close(m.closed)
+			m.closed = nil
+		}
+		m.mu.Unlock()
+	}
+	m.GXMediaWrapper.NotifyMediaStateChange(media, state)
+}
+
+// inject applies a fault to data and returns the data to deliver.
+func (m *Media) inject(direction Direction, data []byte) []chunk {
+	m.mu.Lock()
+	m.counts[direction]++
+	fault := m.fault(direction, m.counts[direction])
+	if (fault == FaultFragment && len(data) < 2) || (fault == FaultCorrupt && len(data) == 0) {
+		fault = FaultNone
+	}
+	var ret []chunk
+	info := fmt.Sprintf("%s %s of %d bytes", direction, fault, len(data))
+	switch fault {
+	case FaultNone:
+		ret = append(ret, chunk{data: data})
+	case FaultDrop:
+	case FaultDelay:
+		ret = append(ret, chunk{data: data, delay: m.delay})
+	case FaultDuplicate:
+		ret = append(ret, chunk{data: data}, chunk{data: bytes.Clone(data)})
+	case FaultFragment:
+		pos := 1 + m.rand.IntN(len(data)-1)
+		ret = append(ret, chunk{data: data[:pos]}, chunk{data: data[pos:]})
+		info = fmt.Sprintf("%s at %d", info, pos)
+	case FaultReorder:
+		// The data is held until the next data has been delivered.
+		if held := m.held[direction]; held != nil {
+			ret = append(ret, chunk{data: held})
+		}
+		m.held[direction] = data
+	case FaultCorrupt:
+		data = bytes.Clone(data)
+		bit := m.rand.IntN(8 * len(data))
+		data[bit/8] ^= 1 << (bit % 8)
+		ret = append(ret, chunk{data: data})
+		info = fmt.Sprintf("%s at bit %d", info, bit)
+	}
+	if fault != FaultReorder && m.held[direction] != nil {
+		ret = append(ret, chunk{data: m.held[direction]})
+		m.held[direction] = nil
+	}
+	m.mu.Unlock()
+	if fault != FaultNone {
+		m.NotifyTrace(m, gxcommon.TraceTypesWarning, info, "")
+	}
+	return ret
+}
+
+// fault returns the fault for the index:th data in direction. The caller
+// must hold m.mu.
+func (m *Media) fault(direction Direction, index int) Fault {
+	for _, it := range m.schedule {
+		if it.Direction == direction && it.Index == index {
+			return it.Fault
+		}
+	}
+	p := m.probabilities[direction]
+	for _, it := range []struct {
+		probability float64
+		fault       Fault
+	}{
+		{p.Drop, FaultDrop},
+		{p.Delay, FaultDelay},
+		{p.Duplicate, FaultDuplicate},
+		{p.Fragment, FaultFragment},
+		{p.Reorder, FaultReorder},
+		{p.Corrupt, FaultCorrupt},
+	} {
+		if it.probability > 0 && m.rand.Float64() < it.probability {
+			return it.fault
+		}
+	}
+	return FaultNone
+}
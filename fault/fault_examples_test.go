@@ -0,0 +1,37 @@
+// Package fault_test holds examples for the fault package.
+package fault_test
+
+import (
+	"fmt"
+
+	"github.com/Gurux/gxcommon-go"
+	"github.com/Gurux/gxcommon-go/fault"
+	"github.com/Gurux/gxcommon-go/memory"
+)
+
+// ExampleMedia_SetSchedule drops the first sent data and duplicates the
+// second received data of a loopback media.
+func ExampleMedia_SetSchedule() {
+	m := fault.New(memory.NewLoopback())
+	m.SetSchedule(
+		fault.Step{Direction: fault.DirectionSend, Index: 1, Fault: fault.FaultDrop},
+		fault.Step{Direction: fault.DirectionReceive, Index: 2, Fault: fault.FaultDuplicate},
+	)
+	_ = m.SetTrace(gxcommon.TraceLevelWarning)
+	m.SetOnTrace(func(_ gxcommon.IGXMedia, e gxcommon.TraceEventArgs) {
+		fmt.Println(e.Data())
+	})
+	m.SetOnReceived(func(_ gxcommon.IGXMedia, e gxcommon.ReceiveEventArgs) {
+		fmt.Printf("received %s\n", e.Data())
+	})
+	_ = m.Open()
+	for _, it := range []string{"A", "B", "C"} {
+		_ = m.Send(it, "")
+	}
+	// Output:
+	// Send Drop of 1 bytes
+	// received B
+	// Receive Duplicate of 1 bytes
+	// received C
+	// received C
+}
@@ -0,0 +1,234 @@
+package fault_test
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"math/bits"
+	"slices"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/Gurux/gxcommon-go"
+	"github.com/Gurux/gxcommon-go/fault"
+	"github.com/Gurux/gxcommon-go/mediatest"
+	"github.com/Gurux/gxcommon-go/memory"
+)
+
+func TestConformance(t *testing.T) {
+	mediatest.RunConformance(t, func(*testing.T) (gxcommon.IGXMedia, gxcommon.IGXMedia) {
+		a, b := memory.NewPair()
+		return fault.New(a), fault.New(b)
+	})
+}
+
+// newPeer returns a collector for the data received by m and opens m.
+func newPeer(t *testing.T, m gxcommon.IGXMedia) *mediatest.Collector {
+	t.Helper()
+	ret := mediatest.NewCollector(m)
+	if err := m.Open(); err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { _ = m.Close() })
+	return ret
+}
+
+// newMedia returns a fault media, its peer and a function that returns the
+// warning traces of the fault media.
+func newMedia(t *testing.T) (*fault.Media, *memory.Media, *mediatest.Collector, func() []string) {
+	t.Helper()
+	a, b := memory.NewPair()
+	p := newPeer(t, b)
+	m := fault.New(a)
+	var mu sync.Mutex
+	var warnings []string
+	_ = m.SetTrace(gxcommon.TraceLevelWarning)
+	m.SetOnTrace(func(_ gxcommon.IGXMedia, e gxcommon.TraceEventArgs) {
+		if e.Type() == gxcommon.TraceTypesWarning {
+			mu.Lock()
+			warnings = append(warnings, e.Data().(string))
+			mu.Unlock()
+		}
+	})
+	if err := m.Open(); err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { _ = m.Close() })
+	return m, b, p, func() []string {
+		mu.Lock()
+		defer mu.Unlock()
+		return slices.Clone(warnings)
+	}
+}
+
+func TestDelay(t *testing.T) {
+	m, _, p, warnings := newMedia(t)
+	m.SetDelay(50 * time.Millisecond)
+	m.SetSchedule(fault.Step{Direction: fault.DirectionSend, Index: 1, Fault: fault.FaultDelay})
+	start := time.Now()
+	if err := m.Send("A", ""); err != nil {
+		t.Fatal(err)
+	}
+	if elapsed := time.Since(start); elapsed < 50*time.Millisecond {
+		t.Errorf("Send returned after %v", elapsed)
+	}
+	if got := p.Received(); len(got) != 1 || string(got[0]) != "A" {
+		t.Errorf("received %q", got)
+	}
+	if got := warnings(); !slices.Equal(got, []string{"Send Delay of 1 bytes"}) {
+		t.Errorf("warnings %q", got)
+	}
+}
+
+func TestDelayClose(t *testing.T) {
+	m, _, p, _ := newMedia(t)
+	m.SetDelay(time.Minute)
+	m.SetSchedule(fault.Step{Direction: fault.DirectionSend, Index: 1, Fault: fault.FaultDelay})
+	time.AfterFunc(20*time.Millisecond, func() { _ = m.Close() })
+	start := time.Now()
+	if err := m.Send("A", ""); !errors.Is(err, gxcommon.ErrConnectionClosed) {
+		t.Errorf("Send: %v", err)
+	}
+	if elapsed := time.Since(start); elapsed > 10*time.Second {
+		t.Errorf("Send returned after %v", elapsed)
+	}
+	if got := p.Received(); len(got) != 0 {
+		t.Errorf("received %q", got)
+	}
+}
+
+func TestDelayReceive(t *testing.T) {
+	m, b, _, warnings := newMedia(t)
+	p := newPeer(t, m)
+	m.SetDelay(50 * time.Millisecond)
+	m.SetSchedule(fault.Step{Direction: fault.DirectionReceive, Index: 1, Fault: fault.FaultDelay})
+	if err := b.Send("A", ""); err != nil {
+		t.Fatal(err)
+	}
+	if got := p.Received(); len(got) != 0 {
+		t.Errorf("received %q before the delay", got)
+	}
+	p.Wait(1, 5*time.Second)
+	if got := p.Received(); len(got) != 1 || string(got[0]) != "A" {
+		t.Errorf("received %q", got)
+	}
+	if got := warnings(); !slices.Equal(got, []string{"Receive Delay of 1 bytes"}) {
+		t.Errorf("warnings %q", got)
+	}
+}
+
+func TestDelayReceiveClose(t *testing.T) {
+	m, b, _, _ := newMedia(t)
+	p := newPeer(t, m)
+	var mu sync.Mutex
+	var states []gxcommon.MediaState
+	m.SetOnMediaStateChange(func(_ gxcommon.IGXMedia, e gxcommon.MediaStateEventArgs) {
+		mu.Lock()
+		states = append(states, e.State())
+		mu.Unlock()
+	})
+	m.SetDelay(20 * time.Millisecond)
+	m.SetSchedule(fault.Step{Direction: fault.DirectionReceive, Index: 1, Fault: fault.FaultDelay})
+	if err := b.Send("A", ""); err != nil {
+		t.Fatal(err)
+	}
+	if err := m.Close(); err != nil {
+		t.Fatal(err)
+	}
+	time.Sleep(100 * time.Millisecond)
+	if got := p.Received(); len(got) != 0 {
+		mu.Lock()
+		defer mu.Unlock()
+		t.Errorf("received %q after states %v", got, states)
+	}
+}
+
+func TestFragment(t *testing.T) {
+	m, b, _, warnings := newMedia(t)
+	p := newPeer(t, m)
+	m.SetSeed(1)
+	m.SetSchedule(fault.Step{Direction: fault.DirectionReceive, Index: 1, Fault: fault.FaultFragment})
+	if err := b.Send("ABCD", ""); err != nil {
+		t.Fatal(err)
+	}
+	got := p.Received()
+	if len(got) != 2 || len(got[0]) == 0 || len(got[1]) == 0 || string(bytes.Join(got, nil)) != "ABCD" {
+		t.Errorf("received %q", got)
+	}
+	if w := warnings(); len(w) != 1 || w[0] != fmt.Sprintf("Receive Fragment of 4 bytes at %d", len(got[0])) {
+		t.Errorf("warnings %q", w)
+	}
+}
+
+func TestReorder(t *testing.T) {
+	m, _, p, warnings := newMedia(t)
+	m.SetSchedule(fault.Step{Direction: fault.DirectionSend, Index: 1, Fault: fault.FaultReorder})
+	for _, it := range []string{"A", "B", "C"} {
+		if err := m.Send(it, ""); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if got := p.Received(); fmt.Sprintf("%s", got) != "[B A C]" {
+		t.Errorf("received %s", got)
+	}
+	if got := warnings(); !slices.Equal(got, []string{"Send Reorder of 1 bytes"}) {
+		t.Errorf("warnings %q", got)
+	}
+}
+
+func TestCorrupt(t *testing.T) {
+	m, _, p, warnings := newMedia(t)
+	m.SetSeed(1)
+	m.SetSchedule(fault.Step{Direction: fault.DirectionSend, Index: 1, Fault: fault.FaultCorrupt})
+	sent := []byte{0x00, 0x00, 0x00, 0x00}
+	if err := m.Send(sent, ""); err != nil {
+		t.Fatal(err)
+	}
+	got := p.Received()
+	if len(got) != 1 || len(got[0]) != 4 {
+		t.Fatalf("received %X", got)
+	}
+	bit := -1
+	for i, it := range got[0] {
+		if it != 0 {
+			if bits.OnesCount8(it) != 1 || bit != -1 {
+				t.Fatalf("received %X", got[0])
+			}
+			bit = 8*i + bits.TrailingZeros8(it)
+		}
+	}
+	if w := warnings(); len(w) != 1 || w[0] != fmt.Sprintf("Send Corrupt of 4 bytes at bit %d", bit) {
+		t.Errorf("warnings %q, corrupted bit %d", w, bit)
+	}
+	if !slices.Equal(sent, []byte{0, 0, 0, 0}) {
+		t.Errorf("sent data was modified: %X", sent)
+	}
+}
+
+func TestProbabilities(t *testing.T) {
+	run := func() ([][]byte, []string) {
+		m, _, p, warnings := newMedia(t)
+		m.SetSeed(42)
+		m.SetProbabilities(fault.DirectionSend, fault.Probabilities{
+			Drop:      0.2,
+			Duplicate: 0.2,
+			Fragment:  0.2,
+			Corrupt:   0.2,
+		})
+		for i := range 50 {
+			if err := m.Send(fmt.Sprintf("data %02d", i), ""); err != nil {
+				t.Fatal(err)
+			}
+		}
+		return p.Received(), warnings()
+	}
+	received, warnings := run()
+	received2, warnings2 := run()
+	if !slices.EqualFunc(received, received2, bytes.Equal) || !slices.Equal(warnings, warnings2) {
+		t.Error("runs with the same seed differ")
+	}
+	if len(warnings) == 0 || len(warnings) == 50 {
+		t.Errorf("%d faults in 50 sends", len(warnings))
+	}
+}
@@ -2,11 +2,19 @@
 package gxcommon_test
 
 import (
+	"context"
+	"encoding/binary"
+	"encoding/json"
+	"errors"
 	"fmt"
+	"log/slog"
+	"os"
+	"time"
 
 	"golang.org/x/text/language"
 
 	"github.com/Gurux/gxcommon-go"
+	"github.com/Gurux/gxcommon-go/memory"
 )
 
 // ExampleGetType demonstrates the generic GetType helper.
@@ -67,3 +75,312 @@ func ExampleLanguage() {
 	// en-US
 	// de
 }
+
+// ExampleGXMediaBase_Receive shows how data pushed into the media base is
+// read in synchronous mode.
+func ExampleGXMediaBase_Receive() {
+	var media gxcommon.GXMediaBase
+	restore := media.GetSynchronous()
+	defer restore()
+	// A concrete media calls NotifyReceived when data arrives.
+	media.NotifyReceived(nil, []byte("Hello\nWorld\n"), "")
+	p := gxcommon.NewReceiveParameters[string]()
+	p.EOP = byte('\n')
+	p.WaitTime = 100
+	ok, err := media.Receive(p)
+	fmt.Printf("%v %v %q\n", ok, err, p.Reply)
+	fmt.Println(media.GetBytesReceived())
+	// Output:
+	// true <nil> "Hello\n"
+	// 12
+}
+
+// ExampleSyncBuffer shows how Count, EOP and Peek select the reply.
+func ExampleSyncBuffer() {
+	buf := gxcommon.NewSyncBuffer()
+	go buf.Append([]byte{0x7E, 0xA0, 0x07, 0x03, 0x7E, 0x01, 0x02})
+
+	p := gxcommon.NewReceiveParameters[[]byte]()
+	p.Count = 2
+	p.Peek = true
+	_, _ = buf.Receive(p)
+	fmt.Println(gxcommon.ToHex(p.Reply.([]byte)))
+
+	p = gxcommon.NewReceiveParameters[[]byte]()
+	p.EOP = byte(0x7E)
+	p.Count = 2
+	_, _ = buf.Receive(p)
+	fmt.Println(gxcommon.ToHex(p.Reply.([]byte)))
+
+	p = gxcommon.NewReceiveParameters[uint16]()
+	p.Count = 2
+	_, _ = buf.Receive(p)
+	fmt.Println(p.Reply)
+	// Output:
+	// 7E A0
+	// 7E A0 07 03 7E
+	// 258
+}
+
+// ExampleSyncBuffer_slice receives two uint16 registers. Count is the
+// number of elements for slice reply types.
+func ExampleSyncBuffer_slice() {
+	buf := gxcommon.NewSyncBuffer()
+	go buf.Append([]byte{0x00, 0x01, 0x12, 0x34, 0xFF})
+
+	p := gxcommon.NewReceiveParameters[[]uint16]()
+	p.Count = 2
+	_, _ = buf.Receive(p)
+	fmt.Printf("%04X\n", p.Reply)
+	// Output:
+	// [0001 1234]
+}
+
+// ExampleWordSwappedBigEndian reads a float32 that a Modbus device sends in
+// two registers, least significant register first.
+func ExampleWordSwappedBigEndian() {
+	buf := gxcommon.NewSyncBuffer()
+	go buf.Append([]byte{0x00, 0x00, 0x40, 0x49})
+
+	p := gxcommon.NewReceiveParameters[float32]()
+	p.Count = 4
+	p.ByteOrder = gxcommon.WordSwappedBigEndian
+	_, _ = buf.Receive(p)
+	fmt.Println(p.Reply)
+
+	b, _ := gxcommon.ToBytes(float32(-2.5), gxcommon.WordSwappedBigEndian)
+	fmt.Println(gxcommon.ToHex(b))
+	// Output:
+	// 3.140625
+	// 00 00 C0 20
+}
+
+// ExampleParseBCD receives a BCD encoded meter serial number and encodes a
+// negative counter value as a packed decimal.
+func ExampleParseBCD() {
+	buf := gxcommon.NewSyncBuffer()
+	go buf.Append([]byte{0x12, 0x34, 0x56, 0x78})
+
+	p := gxcommon.NewReceiveParameters[uint64]()
+	p.Count = 4
+	p.ReplyType = gxcommon.DataTypeBCD
+	_, _ = buf.Receive(p)
+	fmt.Println(p.Reply)
+
+	b, _ := gxcommon.AppendPackedDecimal(nil, -1234, 5)
+	fmt.Println(gxcommon.ToHex(b))
+	// Output:
+	// 12345678
+	// 01 23 4D
+}
+
+// ExampleBitReader decodes a header with a 1-bit flag, a 7-bit address and
+// a big-endian uint16 length.
+func ExampleBitReader() {
+	r := gxcommon.NewBitReader([]byte{0x85, 0x01, 0x02}, gxcommon.BitOrderMSBFirst)
+	final, _ := r.ReadBool()
+	address, _ := r.ReadBits(7)
+	b, _ := r.ReadBytes(2)
+	length, _ := gxcommon.BytesToAny[uint16](b, binary.BigEndian)
+	fmt.Println(final, address, length)
+
+	w := gxcommon.NewBitWriter(gxcommon.BitOrderMSBFirst)
+	_ = w.WriteBool(final)
+	_ = w.WriteBits(address, 7)
+	b, _ = gxcommon.ToBytes(length, binary.BigEndian)
+	_ = w.WriteBytes(b)
+	fmt.Println(gxcommon.ToHex(w.Bytes()))
+	// Output:
+	// true 5 258
+	// 85 01 02
+}
+
+// ExampleSyncBuffer_ReceiveContext shows how a context deadline stops a
+// receive that would otherwise wait forever.
+func ExampleSyncBuffer_ReceiveContext() {
+	buf := gxcommon.NewSyncBuffer()
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	p := gxcommon.NewReceiveParameters[[]byte]()
+	p.EOP = byte(0x7E)
+	_, err := buf.ReceiveContext(ctx, p)
+	fmt.Println(errors.Is(err, context.DeadlineExceeded))
+	// Output:
+	// true
+}
+
+// ExampleHDLCFramer shows how a framer splits a byte stream into frames.
+func ExampleHDLCFramer() {
+	f := &gxcommon.HDLCFramer{}
+	data, _ := f.Encode([]byte{0xA0, 0x7E, 0x01})
+	fmt.Println(gxcommon.ToHex(data))
+	// Noise before the frame is discarded.
+	data = append([]byte{0xFF}, data...)
+	for len(data) != 0 {
+		frame, n, err := f.Next(data)
+		if err != nil || n == 0 {
+			break
+		}
+		if frame != nil {
+			fmt.Println(gxcommon.ToHex(frame))
+		}
+		data = data[n:]
+	}
+	// Output:
+	// 7E A0 7D 5E 01 7E
+	// A0 7E 01
+}
+
+// ExampleSyncBuffer_framer receives length-prefixed frames.
+func ExampleSyncBuffer_framer() {
+	buf := gxcommon.NewSyncBuffer()
+	go buf.Append([]byte{0x00, 0x02, 0x01, 0x02, 0x00, 0x01, 0x03})
+
+	p := gxcommon.NewReceiveParameters[[]byte]()
+	p.Framer = &gxcommon.LengthPrefixFramer{Size: 2}
+	for range 2 {
+		_, _ = buf.Receive(p)
+		fmt.Println(gxcommon.ToHex(p.Reply.([]byte)))
+	}
+	// Output:
+	// 01 02
+	// 03
+}
+
+// ExampleParseSerialSettings parses the compact notation, validates the
+// settings and writes them to JSON.
+func ExampleParseSerialSettings() {
+	s, err := gxcommon.ParseSerialSettings("9600,7E1")
+	fmt.Println(s, err)
+	data, _ := json.Marshal(s)
+	fmt.Println(string(data))
+
+	_, err = gxcommon.ParseSerialSettings("115200 8N1.5")
+	fmt.Println(err)
+	// Output:
+	// 9600 7E1 <nil>
+	// {"baudRate":9600,"dataBits":7,"parity":"Even","stopBits":"One","handshake":"None"}
+	// invalid argument: OnePointFive stop bits with 8 data bits
+}
+
+// ExampleSerialSettings_TransmitTime derives Modbus RTU timeouts and the
+// wait time of a 100 byte reply from the serial settings.
+func ExampleSerialSettings_TransmitTime() {
+	s := gxcommon.DefaultSerialSettings()
+	fmt.Println(s.BitsPerCharacter())
+	fmt.Println(s.CharacterTime())
+	fmt.Println(s.InterFrameTimeout())
+	fmt.Println(gxcommon.WaitTimeOf(s.TransmitTime(100)))
+	// Output:
+	// 10
+	// 1.041667ms
+	// 3.645834ms
+	// 105
+}
+
+// ExampleUnmarshal decodes a fixed-layout meter record.
+func ExampleUnmarshal() {
+	var record struct {
+		Serial  uint32   `gx:"bcd,len=4"`
+		Model   string   `gx:"len=4"`
+		Energy  uint32   `gx:"order=little"`
+		Voltage []uint16 `gx:"prefix=1"`
+	}
+	data := []byte{
+		0x12, 0x34, 0x56, 0x78, 'G', 'X', 0, 0, 0x10, 0x27, 0, 0,
+		3, 0x08, 0xFC, 0x08, 0xFD, 0x08, 0xFA,
+	}
+	if err := gxcommon.Unmarshal(data, &record, binary.BigEndian); err != nil {
+		fmt.Println(err)
+	}
+	fmt.Printf("%+v\n", record)
+	err := gxcommon.Unmarshal(data[:10], &record, binary.BigEndian)
+	fmt.Println(err)
+	// Output:
+	// {Serial:12345678 Model:GX Energy:10000 Voltage:[2300 2301 2298]}
+	// buffer too small: Energy
+}
+
+// ExampleGXByteBuffer encodes and decodes a frame with a byte buffer.
+func ExampleGXByteBuffer() {
+	bb := gxcommon.NewGXByteBuffer(16)
+	bb.SetUint8(0x7E)
+	bb.SetUint16(0xA007)
+	bb.SetString("AB")
+	fmt.Println(bb)
+
+	bb.Compare([]byte{0x7E})
+	v, _ := bb.GetUint16()
+	fmt.Printf("%04X %d\n", v, bb.Available())
+	_, err := bb.GetUint32()
+	fmt.Println(err)
+	// Output:
+	// 7E A0 07 41 42
+	// A007 2
+	// buffer too small: GetUint32
+}
+
+// ExampleNewMedia creates a media from a registered media type and a
+// settings string. The memory package registers itself when imported.
+func ExampleNewMedia() {
+	fmt.Println(gxcommon.MediaTypes())
+	media, err := gxcommon.NewMedia(memory.MediaType, "<Name>Meter</Name>")
+	if err != nil {
+		fmt.Println(err)
+		return
+	}
+	fmt.Println(media.GetMediaType(), media.GetName())
+	_, err = gxcommon.NewMedia("Unknown", "")
+	fmt.Println(errors.Is(err, gxcommon.ErrInvalidArgument))
+	// Output:
+	// [Memory]
+	// Memory Meter
+	// true
+}
+
+// ExampleParseMediaURI parses connection URIs and formats them back in
+// canonical form.
+func ExampleParseMediaURI() {
+	u, err := gxcommon.ParseMediaURI("serial:///dev/ttyUSB0?Parity=Even&baud=9600&stopbits=One")
+	if err != nil {
+		fmt.Println(err)
+		return
+	}
+	fmt.Println(u.Scheme, u.Path, u.BaudRate, u.Parity, u.StopBits)
+	fmt.Println(u)
+
+	u, _ = gxcommon.ParseMediaURI("tcp://10.0.0.5:4059")
+	fmt.Println(u.Host, u)
+
+	_, err = gxcommon.ParseMediaURI("serial:///dev/ttyS0?baud=9601")
+	fmt.Println(err)
+	// Output:
+	// serial /dev/ttyUSB0 9600 Even One
+	// serial:///dev/ttyUSB0?baud=9600&parity=even&stopbits=one
+	// 10.0.0.5:4059 tcp://10.0.0.5:4059
+	// invalid argument: baud=9601
+}
+
+// ExampleAttachSlog logs the traffic of a media with log/slog.
+func ExampleAttachSlog() {
+	logger := slog.New(slog.NewTextHandler(os.Stdout, &slog.HandlerOptions{
+		Level: slog.LevelDebug,
+		// Remove times so that the output is stable.
+		ReplaceAttr: func(_ []string, a slog.Attr) slog.Attr {
+			if a.Key == slog.TimeKey || a.Key == "timestamp" {
+				return slog.Attr{}
+			}
+			return a
+		},
+	}))
+	media := memory.NewLoopback()
+	gxcommon.AttachSlog(media, logger)
+	_ = media.SetTrace(gxcommon.TraceLevelVerbose)
+	_ = media.Open()
+	_ = media.Send([]byte{0x7E, 0xA0}, "")
+	// Output:
+	// level=INFO msg=Info media=Loopback mediaType=Memory traceType=Info data=Opening
+	// level=INFO msg=Info media=Loopback mediaType=Memory traceType=Info data=Open
+	// level=DEBUG msg=Sent media=Loopback mediaType=Memory traceType=Sent data="7E A0"
+	// level=DEBUG msg=Received media=Loopback mediaType=Memory traceType=Received receiver=Loopback data="7E A0"
+}
@@ -0,0 +1,1420 @@
+package gxcommon_test
+
+import (
+	"bytes"
+	"context"
+	"encoding/binary"
+	"encoding/json"
+	"encoding/xml"
+	"errors"
+	"log/slog"
+	"math"
+	"reflect"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/Gurux/gxcommon-go"
+	"github.com/Gurux/gxcommon-go/memory"
+)
+
+func TestRoundTrip(t *testing.T) {
+	tests := []struct {
+		value any
+		dt    gxcommon.DataType
+	}{
+		{"Gurux", gxcommon.DataTypeString},
+		{[]byte{1, 2, 3}, gxcommon.DataTypeBytes},
+		{uint8(0xFE), gxcommon.DataTypeUint8},
+		{int8(-2), gxcommon.DataTypeInt8},
+		{int16(-1234), gxcommon.DataTypeInt16},
+		{int32(-123456), gxcommon.DataTypeInt32},
+		{int64(math.MinInt64), gxcommon.DataTypeInt64},
+		{uint16(0xFEDC), gxcommon.DataTypeUint16},
+		{uint32(0xFEDCBA98), gxcommon.DataTypeUint32},
+		{uint64(math.MaxUint64), gxcommon.DataTypeUint64},
+		{float32(-1.5), gxcommon.DataTypeFloat32},
+		{float64(math.Pi), gxcommon.DataTypeFloat64},
+		{true, gxcommon.DataTypeBool},
+		{false, gxcommon.DataTypeBool},
+		{int(-42), gxcommon.DataTypeInt},
+		{uint(42), gxcommon.DataTypeUint},
+		{'€', gxcommon.DataTypeRune},
+		{[]int8{-1, 2}, gxcommon.DataTypeInt8Slice},
+		{[]int16{-1, 2}, gxcommon.DataTypeInt16Slice},
+		{[]int32{-1, 2}, gxcommon.DataTypeInt32Slice},
+		{[]int64{-1, 2}, gxcommon.DataTypeInt64Slice},
+		{[]uint16{0xFFFE, 2}, gxcommon.DataTypeUint16Slice},
+		{[]uint32{0xFFFFFFFE, 2}, gxcommon.DataTypeUint32Slice},
+		{[]uint64{math.MaxUint64, 2}, gxcommon.DataTypeUint64Slice},
+		{[]float32{-1.5, 2}, gxcommon.DataTypeFloat32Slice},
+		{[]float64{-1.5, math.Inf(1)}, gxcommon.DataTypeFloat64Slice},
+		{[]uint16{}, gxcommon.DataTypeUint16Slice},
+		{uint64(0), gxcommon.DataTypeBCD},
+		{uint64(math.MaxUint64), gxcommon.DataTypeBCD},
+		{int64(0), gxcommon.DataTypePackedDecimal},
+		{int64(-12345), gxcommon.DataTypePackedDecimal},
+		{int64(math.MinInt64), gxcommon.DataTypePackedDecimal},
+		{int64(math.MaxInt64), gxcommon.DataTypePackedDecimal},
+	}
+	for _, order := range gxcommon.AllByteOrders() {
+		for _, tt := range tests {
+			b, err := gxcommon.ToBytes2(tt.value, tt.dt, order)
+			if err != nil {
+				t.Errorf("ToBytes2(%v, %s) failed: %v", tt.value, tt.dt, err)
+				continue
+			}
+			got, err := gxcommon.BytesToAny2(b, tt.dt, order)
+			if err != nil || !reflect.DeepEqual(got, tt.value) {
+				t.Errorf("%s %s: got %v (%T), %v, want %v", order, tt.dt, got, got, err, tt.value)
+			}
+		}
+	}
+}
+
+func TestWordSwapped(t *testing.T) {
+	tests := []struct {
+		order binary.ByteOrder
+		b16   string
+		b32   string
+		b64   string
+	}{
+		{binary.BigEndian, "AA BB", "AA BB CC DD", "11 22 33 44 55 66 77 88"},
+		{binary.LittleEndian, "BB AA", "DD CC BB AA", "88 77 66 55 44 33 22 11"},
+		{gxcommon.WordSwappedBigEndian, "AA BB", "CC DD AA BB", "77 88 55 66 33 44 11 22"},
+		{gxcommon.WordSwappedLittleEndian, "BB AA", "BB AA DD CC", "22 11 44 33 66 55 88 77"},
+	}
+	for _, tt := range tests {
+		o := tt.order.(binary.AppendByteOrder)
+		b := o.AppendUint16(nil, 0xAABB)
+		b = o.AppendUint32(b, 0xAABBCCDD)
+		b = o.AppendUint64(b, 0x1122334455667788)
+		if got, want := gxcommon.ToHex(b), tt.b16+" "+tt.b32+" "+tt.b64; got != want {
+			t.Errorf("%s: got %s, want %s", tt.order, got, want)
+		}
+		buf := make([]byte, 14)
+		tt.order.PutUint16(buf, 0xAABB)
+		tt.order.PutUint32(buf[2:], 0xAABBCCDD)
+		tt.order.PutUint64(buf[6:], 0x1122334455667788)
+		if !reflect.DeepEqual(buf, b) {
+			t.Errorf("%s: Put differs from Append: %X", tt.order, buf)
+		}
+		if tt.order.Uint16(b) != 0xAABB || tt.order.Uint32(b[2:]) != 0xAABBCCDD ||
+			tt.order.Uint64(b[6:]) != 0x1122334455667788 {
+			t.Errorf("%s: invalid decoded values", tt.order)
+		}
+		order, err := gxcommon.ByteOrderParse(tt.order.String())
+		if err != nil || order != tt.order {
+			t.Errorf("ByteOrderParse(%s): got %v, %v", tt.order, order, err)
+		}
+		layout := strings.ReplaceAll(tt.b32, " ", "")
+		layout = strings.NewReplacer("AA", "A", "BB", "B", "CC", "C", "DD", "D").Replace(layout)
+		order, err = gxcommon.ByteOrderParse(strings.ToLower(layout))
+		if err != nil || order != tt.order {
+			t.Errorf("ByteOrderParse(%s): got %v, %v", layout, order, err)
+		}
+	}
+	if _, err := gxcommon.ByteOrderParse("ACBD"); !errors.Is(err, gxcommon.ErrUnknownEnum) {
+		t.Errorf("ByteOrderParse(ACBD): got %v", err)
+	}
+}
+
+func TestByteOrderSettings(t *testing.T) {
+	m := memory.NewLoopback()
+	if err := m.SetSettings("<ByteOrder>CDAB</ByteOrder>"); err != nil {
+		t.Fatal(err)
+	}
+	if got := m.GetSettings(); got != "<Name>Loopback</Name><ByteOrder>WordSwappedBigEndian</ByteOrder>" {
+		t.Errorf("GetSettings: got %s", got)
+	}
+	if err := m.SetSettings("<ByteOrder>Middle</ByteOrder>"); !errors.Is(err, gxcommon.ErrUnknownEnum) {
+		t.Errorf("SetSettings: got %v", err)
+	}
+	if err := m.Open(); err != nil {
+		t.Fatal(err)
+	}
+	defer m.Close()
+	release := m.GetSynchronous()
+	defer release()
+	if err := m.Send(uint32(0xAABBCCDD), ""); err != nil {
+		t.Fatal(err)
+	}
+	p := gxcommon.NewReceiveParameters[[]byte]()
+	p.Count = 4
+	p.Peek = true
+	if _, err := m.Receive(p); err != nil || gxcommon.ToHex(p.Reply.([]byte)) != "CC DD AA BB" {
+		t.Errorf("sent %v, %v", p.Reply, err)
+	}
+	p = gxcommon.NewReceiveParameters[uint32]()
+	p.Count = 4
+	p.Peek = true
+	if _, err := m.Receive(p); err != nil || p.Reply != uint32(0xAABBCCDD) {
+		t.Errorf("media byte order: got %X, %v", p.Reply, err)
+	}
+	p.ByteOrder = binary.BigEndian
+	if _, err := m.Receive(p); err != nil || p.Reply != uint32(0xCCDDAABB) {
+		t.Errorf("ReceiveParameters byte order: got %X, %v", p.Reply, err)
+	}
+	c := memory.NewLoopback()
+	if err := m.Copy(c); err != nil || c.GetByteOrder() != gxcommon.WordSwappedBigEndian {
+		t.Errorf("Copy: got %v, %v", c.GetByteOrder(), err)
+	}
+}
+
+func TestBCD(t *testing.T) {
+	b, err := gxcommon.AppendBCD([]byte{0xFF}, 12345, 6)
+	if err != nil || gxcommon.ToHex(b) != "FF 01 23 45" {
+		t.Errorf("AppendBCD: got %X, %v", b, err)
+	}
+	if b, err = gxcommon.AppendBCD(nil, 12345, 5); err != nil || gxcommon.ToHex(b) != "01 23 45" {
+		t.Errorf("AppendBCD: got %X, %v", b, err)
+	}
+	if _, err = gxcommon.AppendBCD(nil, 12345, 4); !errors.Is(err, gxcommon.ErrArgumentOutOfRange) {
+		t.Errorf("AppendBCD: got %v", err)
+	}
+	if _, err = gxcommon.AppendBCD(nil, 1, 0); !errors.Is(err, gxcommon.ErrInvalidArgument) {
+		t.Errorf("AppendBCD: got %v", err)
+	}
+	if b, err = gxcommon.AppendPackedDecimal(nil, -12345, 5); err != nil || gxcommon.ToHex(b) != "12 34 5D" {
+		t.Errorf("AppendPackedDecimal: got %X, %v", b, err)
+	}
+	if b, err = gxcommon.AppendPackedDecimal(nil, 42, 4); err != nil || gxcommon.ToHex(b) != "00 04 2C" {
+		t.Errorf("AppendPackedDecimal: got %X, %v", b, err)
+	}
+	if _, err = gxcommon.AppendPackedDecimal(nil, -100, 2); !errors.Is(err, gxcommon.ErrArgumentOutOfRange) {
+		t.Errorf("AppendPackedDecimal: got %v", err)
+	}
+	tests := []struct {
+		data  []byte
+		value int64
+		err   error
+	}{
+		{[]byte{0x12, 0x3F}, 123, nil},
+		{[]byte{0x12, 0x3B}, -123, nil},
+		{[]byte{0x0C}, 0, nil},
+		{[]byte{0x12, 0x34}, 0, gxcommon.ErrInvalidArgument},
+		{[]byte{0x1A, 0x3C}, 0, gxcommon.ErrInvalidArgument},
+		{[]byte{}, 0, gxcommon.ErrBufferTooSmall},
+		{[]byte{0x00, 0x92, 0x23, 0x37, 0x20, 0x36, 0x85, 0x47, 0x75, 0x80, 0x8C}, 0, gxcommon.ErrArgumentOutOfRange},
+	}
+	for _, tt := range tests {
+		got, err := gxcommon.ParsePackedDecimal(tt.data)
+		if got != tt.value || !errors.Is(err, tt.err) || (err == nil) != (tt.err == nil) {
+			t.Errorf("ParsePackedDecimal(%X): got %d, %v", tt.data, got, err)
+		}
+	}
+	if _, err = gxcommon.ParseBCD([]byte{0x12, 0xA4}); !errors.Is(err, gxcommon.ErrInvalidArgument) {
+		t.Errorf("ParseBCD: got %v", err)
+	}
+	if _, err = gxcommon.ParseBCD(bytes.Repeat([]byte{0x99}, 11)); !errors.Is(err, gxcommon.ErrArgumentOutOfRange) {
+		t.Errorf("ParseBCD: got %v", err)
+	}
+	if v, err := gxcommon.ParseBCD([]byte{0x00, 0x00, 0x01, 0x23}); err != nil || v != 123 {
+		t.Errorf("ParseBCD: got %d, %v", v, err)
+	}
+}
+
+func TestBitReaderWriter(t *testing.T) {
+	tests := []struct {
+		order gxcommon.BitOrder
+		want  string
+	}{
+		{gxcommon.BitOrderMSBFirst, "A3 FF F0"},
+		{gxcommon.BitOrderLSBFirst, "1D FF 0F"},
+	}
+	for _, tt := range tests {
+		w := gxcommon.NewBitWriter(tt.order)
+		if err := w.WriteBits(0b101, 3); err != nil {
+			t.Fatal(err)
+		}
+		if err := w.WriteBits(0b00011, 5); err != nil {
+			t.Fatal(err)
+		}
+		if err := w.WriteSigned(-1, 12); err != nil {
+			t.Fatal(err)
+		}
+		if got := gxcommon.ToHex(w.Bytes()); got != tt.want || w.Len() != 20 {
+			t.Errorf("%s: got %s, %d bits, want %s", tt.order, got, w.Len(), tt.want)
+		}
+		r := gxcommon.NewBitReader(w.Bytes(), tt.order)
+		a, _ := r.ReadBits(3)
+		b, _ := r.ReadBits(5)
+		c, err := r.ReadSigned(12)
+		if a != 0b101 || b != 0b00011 || c != -1 || err != nil {
+			t.Errorf("%s: got %b %b %d, %v", tt.order, a, b, c, err)
+		}
+		if _, err = r.ReadBits(5); !errors.Is(err, gxcommon.ErrBufferTooSmall) || r.Position() != 20 {
+			t.Errorf("%s: overrun got %v at %d", tt.order, err, r.Position())
+		}
+		if err = w.WriteBits(8, 3); !errors.Is(err, gxcommon.ErrArgumentOutOfRange) {
+			t.Errorf("%s: WriteBits got %v", tt.order, err)
+		}
+		if err = w.WriteSigned(4, 3); !errors.Is(err, gxcommon.ErrArgumentOutOfRange) {
+			t.Errorf("%s: WriteSigned got %v", tt.order, err)
+		}
+	}
+	for _, order := range gxcommon.AllBitOrder() {
+		w := gxcommon.NewBitWriter(order)
+		_ = w.WriteBool(true)
+		_ = w.WriteBits(math.MaxUint64, 64)
+		_ = w.WriteSigned(math.MinInt64, 64)
+		_ = w.WriteSigned(-3, 7)
+		_ = w.WriteBytes([]byte{0x12, 0x34})
+		w.Align()
+		_ = w.WriteBytes([]byte{0x56})
+		r := gxcommon.NewBitReader(w.Bytes(), order)
+		b, _ := r.ReadBool()
+		u, _ := r.ReadBits(64)
+		s1, _ := r.ReadSigned(64)
+		s2, _ := r.ReadSigned(7)
+		data, _ := r.ReadBytes(2)
+		r.Align()
+		last, err := r.ReadBytes(1)
+		if !b || u != math.MaxUint64 || s1 != math.MinInt64 || s2 != -3 ||
+			gxcommon.ToHex(data) != "12 34" || gxcommon.ToHex(last) != "56" || err != nil || r.Remaining() != 0 {
+			t.Errorf("%s: got %v %X %d %d %X %X, %v", order, b, u, s1, s2, data, last, err)
+		}
+	}
+}
+
+func TestMediaURIRoundTrip(t *testing.T) {
+	tests := []struct {
+		value string
+		want  string
+	}{
+		{"serial:COM1?baud=9600", "serial:COM1?baud=9600"},
+		{"serial:///dev/ttyUSB0?Parity=Even&baud=9600", "serial:///dev/ttyUSB0?baud=9600&parity=even"},
+		{"SERIAL:/dev/ttyS0?databits=7&stopbits=Two", "serial:///dev/ttyS0?databits=7&stopbits=two"},
+		{"tcp://10.0.0.5:4059?trace=Verbose", "tcp://10.0.0.5:4059?trace=verbose"},
+		{"tcp://localhost", "tcp://localhost"},
+		{"serial:COM3?handshake=RtsCts", "serial:COM3?handshake=rtscts"},
+		{"memory:", "memory:"},
+	}
+	for _, tt := range tests {
+		u, err := gxcommon.ParseMediaURI(tt.value)
+		if err != nil {
+			t.Errorf("ParseMediaURI(%q): %v", tt.value, err)
+			continue
+		}
+		got := u.String()
+		if got != tt.want {
+			t.Errorf("ParseMediaURI(%q).String() = %q, want %q", tt.value, got, tt.want)
+		}
+		u2, err := gxcommon.ParseMediaURI(got)
+		if err != nil || !reflect.DeepEqual(u, u2) {
+			t.Errorf("round-trip of %q: got %+v, %v, want %+v", tt.value, u2, err, u)
+		}
+	}
+}
+
+func TestFramers(t *testing.T) {
+	tests := []struct {
+		name   string
+		framer gxcommon.Framer
+		data   string
+		frame  string
+		n      int
+		err    error
+	}{
+		{"EOP", &gxcommon.EOPFramer{EOP: []byte{0x0D, 0x0A}}, "31 32 0D 0A 33", "31 32 0D 0A", 4, nil},
+		{"EOP partial", &gxcommon.EOPFramer{EOP: []byte{0x0D, 0x0A}}, "31 32 0D", "", 0, nil},
+		{"EOP missing", &gxcommon.EOPFramer{}, "31", "", 0, gxcommon.ErrInvalidArgument},
+		{"STX/ETX", &gxcommon.STXETXFramer{}, "02 41 42 03 02", "41 42", 4, nil},
+		{"STX/ETX garbage", &gxcommon.STXETXFramer{}, "FF FF 02 41", "", 2, nil},
+		{"STX/ETX no STX", &gxcommon.STXETXFramer{}, "FF FF", "", 2, nil},
+		{"STX/ETX partial", &gxcommon.STXETXFramer{}, "02 41", "", 0, nil},
+		{"STX/ETX BCC", &gxcommon.STXETXFramer{BCC: true}, "02 41 42 03 00", "41 42", 5, nil},
+		{"STX/ETX BCC pending", &gxcommon.STXETXFramer{BCC: true}, "02 41 42 03", "", 0, nil},
+		{"STX/ETX BCC mismatch", &gxcommon.STXETXFramer{BCC: true}, "02 41 42 03 01", "", 5, gxcommon.ErrInvalidArgument},
+		{"SLIP", &gxcommon.SLIPFramer{}, "01 DB DC DB DD C0 02", "01 C0 DB", 6, nil},
+		{"SLIP empty", &gxcommon.SLIPFramer{}, "C0 01", "", 1, nil},
+		{"SLIP partial", &gxcommon.SLIPFramer{}, "01 02", "", 0, nil},
+		{"SLIP invalid escape", &gxcommon.SLIPFramer{}, "01 DB 02 C0", "", 4, gxcommon.ErrInvalidArgument},
+		{"SLIP escape at end", &gxcommon.SLIPFramer{}, "01 DB C0", "", 3, gxcommon.ErrInvalidArgument},
+		{"HDLC", &gxcommon.HDLCFramer{}, "7E A0 7D 5E 7D 5D 7E", "A0 7E 7D", 6, nil},
+		{"HDLC garbage", &gxcommon.HDLCFramer{}, "00 7E A0", "", 1, nil},
+		{"HDLC no flag", &gxcommon.HDLCFramer{}, "00 01", "", 2, nil},
+		{"HDLC empty", &gxcommon.HDLCFramer{}, "7E 7E A0 7E", "", 1, nil},
+		{"HDLC partial", &gxcommon.HDLCFramer{}, "7E A0", "", 0, nil},
+		{"HDLC escape at end", &gxcommon.HDLCFramer{}, "7E A0 7D 7E", "", 3, gxcommon.ErrInvalidArgument},
+		{"length 1", &gxcommon.LengthPrefixFramer{Size: 1}, "02 41 42 43", "41 42", 3, nil},
+		{"length 1 partial", &gxcommon.LengthPrefixFramer{Size: 1}, "02 41", "", 0, nil},
+		{"length 2 big-endian", &gxcommon.LengthPrefixFramer{Size: 2}, "00 01 41", "41", 3, nil},
+		{"length 2 little-endian", &gxcommon.LengthPrefixFramer{Size: 2, Order: binary.LittleEndian}, "01 00 41", "41", 3, nil},
+		{"length 4 big-endian", &gxcommon.LengthPrefixFramer{Size: 4}, "00 00 00 01 41", "41", 5, nil},
+		{"length 4 little-endian", &gxcommon.LengthPrefixFramer{Size: 4, Order: binary.LittleEndian}, "01 00 00 00 41", "41", 5, nil},
+		{"length 2 included", &gxcommon.LengthPrefixFramer{Size: 2, IncludeLength: true}, "00 03 41 42", "41", 3, nil},
+		{"length 4 included", &gxcommon.LengthPrefixFramer{Size: 4, IncludeLength: true, Order: binary.LittleEndian}, "04 00 00 00 41", "", 4, nil},
+		{"length smaller than size", &gxcommon.LengthPrefixFramer{Size: 2, IncludeLength: true}, "00 01 41", "", 2, gxcommon.ErrInvalidArgument},
+		{"length too large", &gxcommon.LengthPrefixFramer{Size: 4, MaxLength: 256}, "FF FF FF FF 41", "", 4, gxcommon.ErrArgumentOutOfRange},
+		{"length invalid size", &gxcommon.LengthPrefixFramer{Size: 3}, "00 00 01 41", "", 0, gxcommon.ErrInvalidArgument},
+	}
+	for _, tt := range tests {
+		bb, err := gxcommon.NewGXByteBufferFromHex(tt.data)
+		if err != nil {
+			t.Fatal(err)
+		}
+		frame, n, err := tt.framer.Next(bb.Data())
+		if n != tt.n || !errors.Is(err, tt.err) || (err == nil) != (tt.err == nil) ||
+			gxcommon.ToHex(frame) != tt.frame {
+			t.Errorf("%s: got %X, %d, %v, want %s, %d, %v", tt.name, frame, n, err, tt.frame, tt.n, tt.err)
+		}
+	}
+}
+
+func TestFramerEncode(t *testing.T) {
+	framers := []gxcommon.Framer{
+		&gxcommon.EOPFramer{EOP: []byte{0x0D, 0x0A}},
+		&gxcommon.STXETXFramer{BCC: true},
+		&gxcommon.SLIPFramer{},
+		&gxcommon.HDLCFramer{},
+		&gxcommon.LengthPrefixFramer{Size: 1},
+		&gxcommon.LengthPrefixFramer{Size: 2, Order: binary.LittleEndian, IncludeLength: true},
+		&gxcommon.LengthPrefixFramer{Size: 4},
+	}
+	payload := []byte{0x41, 0x7E, 0x7D, 0xC0, 0xDB, 0x02}
+	for _, f := range framers {
+		frame, err := f.Encode(payload)
+		if err != nil {
+			t.Errorf("%T: %v", f, err)
+			continue
+		}
+		// SLIP frames start with END, which is discarded first.
+		var got []byte
+		end := 0
+		for end < len(frame) {
+			var n int
+			got, n, err = f.Next(frame[end:])
+			end += n
+			if got != nil || err != nil || n == 0 {
+				break
+			}
+		}
+		want := payload
+		if it, ok := f.(*gxcommon.EOPFramer); ok {
+			want = append(bytes.Clone(payload), it.EOP...)
+		}
+		// The closing HDLC flag is left for the next frame.
+		if _, ok := f.(*gxcommon.HDLCFramer); ok {
+			end++
+		}
+		if err != nil || end != len(frame) || !bytes.Equal(got, want) {
+			t.Errorf("%T: got %X, %d, %v", f, got, end, err)
+		}
+	}
+	if _, err := (&gxcommon.STXETXFramer{}).Encode([]byte{0x03}); !errors.Is(err, gxcommon.ErrInvalidArgument) {
+		t.Errorf("STXETXFramer.Encode with ETX: %v", err)
+	}
+	if _, err := (&gxcommon.LengthPrefixFramer{Size: 1}).Encode(make([]byte, 256)); !errors.Is(err, gxcommon.ErrArgumentOutOfRange) {
+		t.Errorf("LengthPrefixFramer.Encode of too long payload: %v", err)
+	}
+	if _, err := (&gxcommon.LengthPrefixFramer{Size: 2, MaxLength: 4}).Encode(payload); !errors.Is(err, gxcommon.ErrArgumentOutOfRange) {
+		t.Errorf("LengthPrefixFramer.Encode over MaxLength: %v", err)
+	}
+	if _, err := (&gxcommon.LengthPrefixFramer{Size: -1}).Encode(payload); !errors.Is(err, gxcommon.ErrInvalidArgument) {
+		t.Errorf("LengthPrefixFramer.Encode with invalid Size: %v", err)
+	}
+}
+
+// receiveOnly hides the ReceiveContext method of a media.
+type receiveOnly struct {
+	gxcommon.IGXMedia
+}
+
+func TestMediaContextDeadline(t *testing.T) {
+	m := memory.NewLoopback()
+	if err := m.Open(); err != nil {
+		t.Fatal(err)
+	}
+	defer m.Close()
+	release := m.GetSynchronous()
+	defer release()
+	mc := gxcommon.MediaContext(receiveOnly{m})
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Millisecond)
+	defer cancel()
+	p := gxcommon.NewReceiveParameters[[]byte]()
+	p.Count = 1
+	start := time.Now()
+	if _, err := mc.ReceiveContext(ctx, p); !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("ReceiveContext: %v", err)
+	}
+	// The poll interval is 100 ms, so returning before it shows that the
+	// poll was limited to the deadline of ctx.
+	if elapsed := time.Since(start); elapsed >= 90*time.Millisecond {
+		t.Errorf("ReceiveContext returned after %v", elapsed)
+	}
+}
+
+func TestGXMediaBaseEop(t *testing.T) {
+	var media gxcommon.GXMediaBase
+	var packets []string
+	media.SetOnReceived(func(_ gxcommon.IGXMedia, e gxcommon.ReceiveEventArgs) {
+		packets = append(packets, string(e.Data()))
+	})
+	media.SetEop("\r\n")
+	// A packet that is split across chunks is raised once it is complete.
+	for _, it := range []string{"AB\r", "\nCD\r\nE", "F", "\r\n"} {
+		media.NotifyReceived(nil, []byte(it), "")
+	}
+	if want := []string{"AB\r\n", "CD\r\n", "EF\r\n"}; !reflect.DeepEqual(packets, want) {
+		t.Errorf("packets = %q, want %q", packets, want)
+	}
+	if media.GetBytesReceived() != 12 {
+		t.Errorf("GetBytesReceived() = %d", media.GetBytesReceived())
+	}
+	// Changing EOP drops the incomplete packet.
+	packets = nil
+	media.NotifyReceived(nil, []byte("G"), "")
+	media.SetEop(byte(0x7E))
+	media.NotifyReceived(nil, []byte("H\x7E"), "")
+	if want := []string{"H\x7E"}; !reflect.DeepEqual(packets, want) {
+		t.Errorf("packets after SetEop = %q, want %q", packets, want)
+	}
+	// Without EOP every chunk is raised as it is.
+	packets = nil
+	media.SetEop(nil)
+	media.NotifyReceived(nil, []byte("I\r"), "")
+	media.NotifyReceived(nil, []byte("\n"), "")
+	if want := []string{"I\r", "\n"}; !reflect.DeepEqual(packets, want) {
+		t.Errorf("packets without EOP = %q, want %q", packets, want)
+	}
+}
+
+func TestGXMediaBaseSetTrace(t *testing.T) {
+	var media gxcommon.GXMediaBase
+	for _, it := range gxcommon.AllTraceLevel() {
+		if err := media.SetTrace(it); err != nil || media.GetTrace() != it {
+			t.Errorf("SetTrace(%v) = %v, GetTrace() = %v", it, err, media.GetTrace())
+		}
+	}
+	for _, it := range []gxcommon.TraceLevel{gxcommon.TraceLevelOff - 1, gxcommon.TraceLevelVerbose + 1} {
+		if err := media.SetTrace(it); !errors.Is(err, gxcommon.ErrArgumentOutOfRange) {
+			t.Errorf("SetTrace(%d) = %v", it, err)
+		}
+		if media.GetTrace() != gxcommon.TraceLevelVerbose {
+			t.Errorf("SetTrace(%d) changed the level to %v", it, media.GetTrace())
+		}
+	}
+}
+
+func TestGXMediaBaseGetSynchronous(t *testing.T) {
+	var media gxcommon.GXMediaBase
+	received := 0
+	media.SetOnReceived(func(gxcommon.IGXMedia, gxcommon.ReceiveEventArgs) {
+		received++
+	})
+	outer := media.GetSynchronous()
+	inner := media.GetSynchronous()
+	inner()
+	// Calling a restore function twice must not end the outer call.
+	inner()
+	if !media.IsSynchronous() {
+		t.Fatal("inner restore ended synchronous mode")
+	}
+	media.NotifyReceived(nil, []byte{1, 2}, "")
+	if received != 0 {
+		t.Errorf("received event raised in synchronous mode")
+	}
+	args := gxcommon.NewReceiveParameters[[]byte]()
+	args.Count = 2
+	args.WaitTime = 0
+	if ok, err := media.Receive(args); !ok || err != nil || !bytes.Equal(args.Reply.([]byte), []byte{1, 2}) {
+		t.Errorf("Receive() = %v, %v, %v", ok, err, args.Reply)
+	}
+	outer()
+	if media.IsSynchronous() {
+		t.Fatal("synchronous mode not restored")
+	}
+	media.NotifyReceived(nil, []byte{3}, "")
+	if received != 1 {
+		t.Errorf("received events = %d after restore, want 1", received)
+	}
+	if ok, _ := media.Receive(args); ok {
+		t.Errorf("asynchronous data was added to the receive buffer: %v", args.Reply)
+	}
+}
+
+func TestSyncBufferReceive(t *testing.T) {
+	tests := []struct {
+		name   string
+		data   string
+		args   gxcommon.ReceiveParameters
+		ok     bool
+		reply  any
+		err    error
+		remain string
+	}{
+		{"Count", "01 02 03", gxcommon.ReceiveParameters{Count: 2}, true, []byte{1, 2}, nil, "03"},
+		{"Peek", "01 02 03", gxcommon.ReceiveParameters{Count: 2, Peek: true}, true, []byte{1, 2}, nil, "01 02 03"},
+		{"Count not available", "01", gxcommon.ReceiveParameters{Count: 2}, false, nil, nil, "01"},
+		{"EOP byte", "01 7E 02 7E", gxcommon.ReceiveParameters{EOP: byte(0x7E)}, true, []byte{1, 0x7E}, nil, "02 7E"},
+		{"EOP string", "41 0D 0A 42", gxcommon.ReceiveParameters{EOP: "\r\n"}, true, []byte("A\r\n"), nil, "42"},
+		{"EOP not found", "01 02", gxcommon.ReceiveParameters{EOP: []byte{0x0D, 0x0A}}, false, nil, nil, "01 02"},
+		{"Count and EOP", "7E 01 7E 02", gxcommon.ReceiveParameters{Count: 2, EOP: byte(0x7E)}, true, []byte{0x7E, 1, 0x7E}, nil, "02"},
+		{"Count and EOP too short", "7E 01", gxcommon.ReceiveParameters{Count: 2, EOP: byte(0x7E)}, false, nil, nil, "7E 01"},
+		{"Count and EOP at Count", "01 7E 7E", gxcommon.ReceiveParameters{Count: 2, EOP: byte(0x7E)}, true, []byte{1, 0x7E}, nil, "7E"},
+		{"AllData found", "01 7E 02", gxcommon.ReceiveParameters{EOP: byte(0x7E), AllData: true}, true, []byte{1, 0x7E, 2}, nil, ""},
+		{"AllData timeout", "01 02", gxcommon.ReceiveParameters{EOP: byte(0x7E), AllData: true, WaitTime: 20}, false, []byte{1, 2}, nil, ""},
+		{"AllData without data", "", gxcommon.ReceiveParameters{EOP: byte(0x7E), AllData: true, WaitTime: 20}, false, nil, nil, ""},
+		{"Timeout", "01", gxcommon.ReceiveParameters{Count: 2, WaitTime: 20}, false, nil, nil, "01"},
+		{"ReplyType", "01 02 03", gxcommon.ReceiveParameters{Count: 2, ReplyType: gxcommon.DataTypeUint16}, true, uint16(0x0102), nil, "03"},
+		{"ReplyType slice", "01 02 03 04 05", gxcommon.ReceiveParameters{Count: 2, ReplyType: gxcommon.DataTypeUint16Slice}, true, []uint16{0x0102, 0x0304}, nil, "05"},
+		{"ByteOrder", "01 02", gxcommon.ReceiveParameters{Count: 2, Reply: uint16(0), ByteOrder: binary.LittleEndian}, true, uint16(0x0201), nil, ""},
+		{"Framer", "01 AA 02 03", gxcommon.ReceiveParameters{Framer: &gxcommon.LengthPrefixFramer{Size: 1}}, true, []byte{0xAA}, nil, "02 03"},
+		{"Framer skips garbage", "AA 02 41 03 42", gxcommon.ReceiveParameters{Framer: &gxcommon.STXETXFramer{}}, true, []byte{0x41}, nil, "42"},
+		{"Framer BCC error", "02 41 03 00 02", gxcommon.ReceiveParameters{Framer: &gxcommon.STXETXFramer{BCC: true}}, false, nil, gxcommon.ErrInvalidArgument, "02"},
+		{"Framer error", "05 01 02 01 AA", gxcommon.ReceiveParameters{Framer: &gxcommon.LengthPrefixFramer{Size: 1, MaxLength: 2}}, false, nil, gxcommon.ErrArgumentOutOfRange, "01 02 01 AA"},
+		{"Framer error with Peek", "05 01 02", gxcommon.ReceiveParameters{Framer: &gxcommon.LengthPrefixFramer{Size: 1, MaxLength: 2}, Peek: true}, false, nil, gxcommon.ErrArgumentOutOfRange, "05 01 02"},
+		{"Nothing to receive", "01", gxcommon.ReceiveParameters{}, false, nil, gxcommon.ErrInvalidArgument, "01"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			buf := gxcommon.NewSyncBuffer()
+			data, err := gxcommon.NewGXByteBufferFromHex(tt.data)
+			if err != nil {
+				t.Fatal(err)
+			}
+			buf.Append(data.Data())
+			args := tt.args
+			ok, err := buf.Receive(&args)
+			if ok != tt.ok || !errors.Is(err, tt.err) || (err == nil) != (tt.err == nil) {
+				t.Fatalf("Receive() = %v, %v, want %v, %v", ok, err, tt.ok, tt.err)
+			}
+			if !reflect.DeepEqual(args.Reply, tt.reply) {
+				t.Errorf("Reply = %v (%T), want %v (%T)", args.Reply, args.Reply, tt.reply, tt.reply)
+			}
+			remain := gxcommon.NewReceiveParameters[[]byte]()
+			remain.AllData = true
+			remain.WaitTime = 0
+			remain.Peek = true
+			if _, err = buf.Receive(remain); err != nil {
+				t.Fatal(err)
+			}
+			if got, _ := remain.Reply.([]byte); gxcommon.ToHex(got) != tt.remain || buf.Len() != len(got) {
+				t.Errorf("buffer = %q, want %q", gxcommon.ToHex(got), tt.remain)
+			}
+		})
+	}
+}
+
+func TestSyncBufferWait(t *testing.T) {
+	buf := gxcommon.NewSyncBuffer()
+	done := make(chan error, 1)
+	args := gxcommon.NewReceiveParameters[[]byte]()
+	args.Count = 3
+	go func() {
+		_, err := buf.Receive(args)
+		done <- err
+	}()
+	// Infinite wait is woken up by each Append until the reply is complete.
+	buf.Append([]byte{1})
+	buf.Append([]byte{2, 3, 4})
+	select {
+	case err := <-done:
+		if err != nil || !bytes.Equal(args.Reply.([]byte), []byte{1, 2, 3}) {
+			t.Fatalf("Receive() = %v, %v", args.Reply, err)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("Receive() with WaitTime -1 was not woken up by Append")
+	}
+	// WaitTime 0 checks only the buffered data.
+	args = gxcommon.NewReceiveParameters[[]byte]()
+	args.Count = 2
+	args.WaitTime = 0
+	start := time.Now()
+	if ok, err := buf.Receive(args); ok || err != nil || args.Reply != nil || buf.Len() != 1 {
+		t.Errorf("Receive() with WaitTime 0 = %v, %v, %v", ok, err, args.Reply)
+	}
+	if d := time.Since(start); d > time.Second {
+		t.Errorf("Receive() with WaitTime 0 waited %v", d)
+	}
+}
+
+func TestSyncBufferClose(t *testing.T) {
+	buf := gxcommon.NewSyncBuffer()
+	buf.Append([]byte{1})
+	done := make(chan error, 1)
+	go func() {
+		args := gxcommon.NewReceiveParameters[[]byte]()
+		args.Count = 2
+		_, err := buf.Receive(args)
+		done <- err
+	}()
+	buf.Close()
+	select {
+	case err := <-done:
+		if !errors.Is(err, gxcommon.ErrConnectionClosed) {
+			t.Fatalf("Receive() after Close = %v", err)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("Close did not release a blocked Receive")
+	}
+	args := gxcommon.NewReceiveParameters[[]byte]()
+	args.Count = 1
+	args.WaitTime = 0
+	if _, err := buf.Receive(args); !errors.Is(err, gxcommon.ErrConnectionClosed) {
+		t.Errorf("Receive() on a closed buffer = %v", err)
+	}
+	// Reset clears the data and opens the buffer again.
+	buf.Reset()
+	buf.Append([]byte{2})
+	if ok, err := buf.Receive(args); !ok || err != nil || !bytes.Equal(args.Reply.([]byte), []byte{2}) {
+		t.Errorf("Receive() after Reset = %v, %v, %v", ok, err, args.Reply)
+	}
+}
+
+func TestSyncBufferContext(t *testing.T) {
+	buf := gxcommon.NewSyncBuffer()
+	buf.Append([]byte{1})
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error, 1)
+	go func() {
+		args := gxcommon.NewReceiveParameters[[]byte]()
+		args.Count = 2
+		_, err := buf.ReceiveContext(ctx, args)
+		done <- err
+	}()
+	cancel()
+	select {
+	case err := <-done:
+		if !errors.Is(err, context.Canceled) {
+			t.Fatalf("ReceiveContext() after cancel = %v", err)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("cancel did not release a blocked ReceiveContext")
+	}
+	if buf.Len() != 1 {
+		t.Errorf("cancel changed the buffer to %d bytes", buf.Len())
+	}
+}
+
+// recordHandler is a slog.Handler that collects the records.
+type recordHandler struct {
+	level   slog.Level
+	records []slog.Record
+}
+
+func (h *recordHandler) Enabled(_ context.Context, level slog.Level) bool {
+	return level >= h.level
+}
+
+func (h *recordHandler) Handle(_ context.Context, r slog.Record) error {
+	h.records = append(h.records, r)
+	return nil
+}
+
+func (h *recordHandler) WithAttrs([]slog.Attr) slog.Handler {
+	return h
+}
+
+func (h *recordHandler) WithGroup(string) slog.Handler {
+	return h
+}
+
+// attrs returns the attributes of r as strings.
+func attrs(r slog.Record) map[string]string {
+	ret := map[string]string{}
+	r.Attrs(func(a slog.Attr) bool {
+		ret[a.Key] = a.Value.String()
+		return true
+	})
+	return ret
+}
+
+func TestSlogLevel(t *testing.T) {
+	tests := []struct {
+		level gxcommon.TraceLevel
+		want  slog.Level
+	}{
+		{gxcommon.TraceLevelOff, slog.LevelError + 4},
+		{gxcommon.TraceLevelError, slog.LevelError},
+		{gxcommon.TraceLevelWarning, slog.LevelWarn},
+		{gxcommon.TraceLevelInfo, slog.LevelInfo},
+		{gxcommon.TraceLevelVerbose, slog.LevelDebug},
+		{gxcommon.TraceLevelVerbose + 1, slog.LevelError + 4},
+	}
+	for _, tt := range tests {
+		if got := gxcommon.SlogLevel(tt.level); got != tt.want {
+			t.Errorf("SlogLevel(%d) = %v, want %v", tt.level, got, tt.want)
+		}
+	}
+}
+
+func TestSlogTraceHandler(t *testing.T) {
+	h := &recordHandler{level: slog.LevelDebug}
+	handler := gxcommon.SlogTraceHandler(slog.New(h))
+	media := memory.NewLoopback()
+	_ = media.SetTrace(gxcommon.TraceLevelWarning)
+	// Events above the trace level of the media are ignored.
+	handler(media, *gxcommon.NewTraceEventArgs(gxcommon.TraceTypesInfo, "ignored", ""))
+	handler(media, *gxcommon.NewTraceEventArgs(gxcommon.TraceTypesSent, []byte{1}, ""))
+	handler(media, *gxcommon.NewTraceEventArgs(gxcommon.TraceTypesWarning, "late", ""))
+	_ = media.SetTrace(gxcommon.TraceLevelVerbose)
+	handler(media, *gxcommon.NewTraceEventArgs(gxcommon.TraceTypesReceived, []byte{0x7E, 0xA0}, "peer"))
+	// Events without a media are not filtered.
+	handler(nil, *gxcommon.NewTraceEventArgs(gxcommon.TraceTypesError, nil, ""))
+	if len(h.records) != 3 {
+		t.Fatalf("%d records, want 3", len(h.records))
+	}
+	want := []struct {
+		level slog.Level
+		msg   string
+		attrs map[string]string
+	}{
+		{slog.LevelWarn, "Warning", map[string]string{"media": "Loopback", "mediaType": "Memory", "traceType": "Warning", "data": "late"}},
+		{slog.LevelDebug, "Received", map[string]string{"media": "Loopback", "mediaType": "Memory", "traceType": "Received", "receiver": "peer", "data": "7E A0"}},
+		{slog.LevelError, "Error", map[string]string{"traceType": "Error"}},
+	}
+	for i, it := range want {
+		r := h.records[i]
+		got := attrs(r)
+		if _, ok := got["timestamp"]; !ok {
+			t.Errorf("record %d has no timestamp", i)
+		}
+		delete(got, "timestamp")
+		if r.Level != it.level || r.Message != it.msg || !reflect.DeepEqual(got, it.attrs) {
+			t.Errorf("record %d = %v %q %v, want %v %q %v", i, r.Level, r.Message, got, it.level, it.msg, it.attrs)
+		}
+	}
+	// Events below the level of the logger are ignored.
+	h = &recordHandler{level: slog.LevelInfo}
+	handler = gxcommon.SlogTraceHandler(slog.New(h))
+	handler(media, *gxcommon.NewTraceEventArgs(gxcommon.TraceTypesSent, []byte{1}, ""))
+	if len(h.records) != 0 {
+		t.Errorf("debug record logged at info level: %v", h.records)
+	}
+}
+
+func TestSlogErrorHandler(t *testing.T) {
+	h := &recordHandler{level: slog.LevelError}
+	media := memory.NewLoopback()
+	gxcommon.AttachSlog(media, slog.New(h))
+	media.NotifyError(media, gxcommon.ErrConnectionClosed)
+	if len(h.records) != 1 {
+		t.Fatalf("%d records, want 1", len(h.records))
+	}
+	r := h.records[0]
+	want := map[string]string{"media": "Loopback", "mediaType": "Memory", "error": gxcommon.ErrConnectionClosed.Error()}
+	if got := attrs(r); r.Level != slog.LevelError || r.Message != "Media error" || !reflect.DeepEqual(got, want) {
+		t.Errorf("record = %v %q %v, want %v", r.Level, r.Message, got, want)
+	}
+}
+
+func TestRegisterMediaPanics(t *testing.T) {
+	tests := []struct {
+		name      string
+		mediaType string
+		factory   gxcommon.MediaFactory
+	}{
+		{"duplicate", "Memory", func() gxcommon.IGXMedia { return memory.NewLoopback() }},
+		{"duplicate with other case", "mEMORY", func() gxcommon.IGXMedia { return memory.NewLoopback() }},
+		{"nil factory", "RegistryTest", nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			defer func() {
+				if recover() == nil {
+					t.Error("RegisterMedia did not panic")
+				}
+			}()
+			gxcommon.RegisterMedia(tt.mediaType, tt.factory)
+		})
+	}
+	if slices.Contains(gxcommon.MediaTypes(), "RegistryTest") {
+		t.Error("nil factory was registered")
+	}
+}
+
+func TestNewMedia(t *testing.T) {
+	for _, it := range []string{"Memory", "memory", "MEMORY"} {
+		m, err := gxcommon.NewMedia(it, "<Name>Meter</Name>")
+		if err != nil {
+			t.Fatalf("NewMedia(%q): %v", it, err)
+		}
+		if m.GetMediaType() != memory.MediaType || m.GetName() != "Meter" {
+			t.Errorf("NewMedia(%q) = %s %s", it, m.GetMediaType(), m.GetName())
+		}
+	}
+	if m, err := gxcommon.NewMedia("Memory", ""); err != nil || m.GetName() != "Loopback" {
+		t.Errorf("NewMedia without settings = %v, %v", m, err)
+	}
+	tests := []struct {
+		mediaType string
+		settings  string
+		err       error
+	}{
+		{"Unknown", "", gxcommon.ErrInvalidArgument},
+		{"Memory", "<Name>", gxcommon.ErrInvalidArgument},
+		{"Memory", "<ByteOrder>Middle</ByteOrder>", gxcommon.ErrUnknownEnum},
+	}
+	for _, tt := range tests {
+		if m, err := gxcommon.NewMedia(tt.mediaType, tt.settings); m != nil || !errors.Is(err, tt.err) {
+			t.Errorf("NewMedia(%q, %q) = %v, %v, want %v", tt.mediaType, tt.settings, m, err, tt.err)
+		}
+	}
+	if !slices.Contains(gxcommon.MediaTypes(), memory.MediaType) {
+		t.Errorf("MediaTypes() = %q", gxcommon.MediaTypes())
+	}
+}
+
+func TestParseSerialSettings(t *testing.T) {
+	tests := []struct {
+		value string
+		want  string
+		err   error
+	}{
+		{"9600,8N1", "9600 8N1", nil},
+		{"115200 7e1", "115200 7E1", nil},
+		{"300\t7E2 XON/XOFF", "300 7E2 XonXoff", nil},
+		{"1200,5O1.5", "1200 5O1.5", nil},
+		{"9600, 8S1 RtsCts", "9600 8S1 RtsCts", nil},
+		{"9600 8X1", "", gxcommon.ErrInvalidArgument},
+		{"9600 9N1", "", gxcommon.ErrInvalidArgument},
+		{"9600 4N1", "", gxcommon.ErrInvalidArgument},
+		{"9600 8N3", "", gxcommon.ErrInvalidArgument},
+		{"9600 8N", "", gxcommon.ErrInvalidArgument},
+		{"8N1.5", "", gxcommon.ErrInvalidArgument},
+		{"9600", "", gxcommon.ErrInvalidArgument},
+		{"", "", gxcommon.ErrInvalidArgument},
+		{"9600 8N1 RtsCts extra", "", gxcommon.ErrInvalidArgument},
+		{"9600 8N1.5", "", gxcommon.ErrInvalidArgument},
+		{"9600 5N2", "", gxcommon.ErrInvalidArgument},
+		{"fast 8N1", "", gxcommon.ErrUnknownEnum},
+		{"9600 8N1 Maybe", "", gxcommon.ErrUnknownEnum},
+	}
+	for _, tt := range tests {
+		s, err := gxcommon.ParseSerialSettings(tt.value)
+		if tt.err != nil {
+			if !errors.Is(err, tt.err) {
+				t.Errorf("ParseSerialSettings(%q): got %v, want %v", tt.value, err, tt.err)
+			}
+			continue
+		}
+		if err != nil || s.String() != tt.want {
+			t.Errorf("ParseSerialSettings(%q): got %q, %v, want %q", tt.value, s, err, tt.want)
+			continue
+		}
+		if err = s.Validate(); err != nil {
+			t.Errorf("Validate(%q): %v", tt.value, err)
+		}
+		if s2, err := gxcommon.ParseSerialSettings(s.String()); err != nil || s2 != s {
+			t.Errorf("round-trip of %q: got %v, %v", tt.value, s2, err)
+		}
+		b, err := xml.Marshal(s)
+		if err != nil {
+			t.Fatal(err)
+		}
+		var x gxcommon.SerialSettings
+		if err = xml.Unmarshal(b, &x); err != nil || x != s {
+			t.Errorf("XML round-trip of %s: got %v, %v", b, x, err)
+		}
+		if b, err = json.Marshal(s); err != nil {
+			t.Fatal(err)
+		}
+		var j gxcommon.SerialSettings
+		if err = json.Unmarshal(b, &j); err != nil || j != s {
+			t.Errorf("JSON round-trip of %s: got %v, %v", b, j, err)
+		}
+	}
+}
+
+func TestSerialSettingsValidate(t *testing.T) {
+	tests := []struct {
+		s   gxcommon.SerialSettings
+		err error
+	}{
+		{gxcommon.DefaultSerialSettings(), nil},
+		{gxcommon.SerialSettings{BaudRate: 9600, DataBits: gxcommon.DataBits8, StopBits: gxcommon.StopBitsTwo}, nil},
+		{gxcommon.SerialSettings{DataBits: gxcommon.DataBits8, StopBits: gxcommon.StopBitsOne}, gxcommon.ErrArgumentOutOfRange},
+		{gxcommon.SerialSettings{BaudRate: 9600, DataBits: 9, StopBits: gxcommon.StopBitsOne}, gxcommon.ErrArgumentOutOfRange},
+		{gxcommon.SerialSettings{BaudRate: 9600, DataBits: gxcommon.DataBits8, Parity: 9, StopBits: gxcommon.StopBitsOne}, gxcommon.ErrUnknownEnum},
+		{gxcommon.SerialSettings{BaudRate: 9600, DataBits: gxcommon.DataBits8, StopBits: 9}, gxcommon.ErrUnknownEnum},
+		{gxcommon.SerialSettings{BaudRate: 9600, DataBits: gxcommon.DataBits8, StopBits: gxcommon.StopBitsOne, Handshake: 9}, gxcommon.ErrUnknownEnum},
+		{gxcommon.SerialSettings{BaudRate: 9600, DataBits: gxcommon.DataBits8, StopBits: gxcommon.StopBitsNone}, gxcommon.ErrInvalidArgument},
+		{gxcommon.SerialSettings{BaudRate: 9600, DataBits: gxcommon.DataBits8, StopBits: gxcommon.StopBitsOnePointFive}, gxcommon.ErrInvalidArgument},
+		{gxcommon.SerialSettings{BaudRate: 9600, DataBits: gxcommon.DataBits5, StopBits: gxcommon.StopBitsTwo}, gxcommon.ErrInvalidArgument},
+	}
+	for _, tt := range tests {
+		err := tt.s.Validate()
+		if !errors.Is(err, tt.err) || (err == nil) != (tt.err == nil) {
+			t.Errorf("Validate(%+v): got %v, want %v", tt.s, err, tt.err)
+		}
+	}
+	var s gxcommon.SerialSettings
+	err := xml.Unmarshal([]byte("<SerialSettings><BaudRate>9600</BaudRate><DataBits>8</DataBits><Parity>Odd</Parity>"+
+		"<StopBits>Two</StopBits><Handshake>RtsCts</Handshake></SerialSettings>"), &s)
+	if err != nil || s.String() != "9600 8O2 RtsCts" {
+		t.Errorf("XML: got %v, %v", s, err)
+	}
+	if err = xml.Unmarshal([]byte("<SerialSettings><Parity>Sometimes</Parity></SerialSettings>"), &s); !errors.Is(err, gxcommon.ErrUnknownEnum) {
+		t.Errorf("XML with unknown parity: %v", err)
+	}
+}
+
+func TestSerialTiming(t *testing.T) {
+	tests := []struct {
+		settings  string
+		bits      float64
+		character time.Duration
+		t15       time.Duration
+		t35       time.Duration
+	}{
+		{"9600 8N1", 10, 1041667, 1562500, 3645834},
+		{"1200 7E2", 11, 9166667, 13750000, 32083334},
+		{"300 5N1.5", 7.5, 25000000, 37500000, 87500000},
+		{"19200 8E1", 11, 572917, 859375, 2005209},
+		{"19200 8N2", 11, 572917, 859375, 2005209},
+		{"38400 8N1", 10, 260417, 750 * time.Microsecond, 1750 * time.Microsecond},
+		{"115200 8E1", 11, 95487, 750 * time.Microsecond, 1750 * time.Microsecond},
+	}
+	for _, tt := range tests {
+		s, err := gxcommon.ParseSerialSettings(tt.settings)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got := s.BitsPerCharacter(); got != tt.bits {
+			t.Errorf("%s: BitsPerCharacter = %v, want %v", tt.settings, got, tt.bits)
+		}
+		if got := s.CharacterTime(); got != tt.character {
+			t.Errorf("%s: CharacterTime = %v, want %v", tt.settings, got, tt.character)
+		}
+		if got := s.InterCharacterTimeout(); got != tt.t15 {
+			t.Errorf("%s: InterCharacterTimeout = %v, want %v", tt.settings, got, tt.t15)
+		}
+		if got := s.InterFrameTimeout(); got != tt.t35 {
+			t.Errorf("%s: InterFrameTimeout = %v, want %v", tt.settings, got, tt.t35)
+		}
+		if got := gxcommon.CharacterTime(s.BaudRate, s.DataBits, s.Parity, s.StopBits); got != tt.character {
+			t.Errorf("%s: CharacterTime function = %v, want %v", tt.settings, got, tt.character)
+		}
+	}
+	s := gxcommon.DefaultSerialSettings()
+	if got := s.TransmitTime(10); got != 10416667 {
+		t.Errorf("TransmitTime(10) = %v", got)
+	}
+	if got := s.TransmitTime(0); got != 0 {
+		t.Errorf("TransmitTime(0) = %v", got)
+	}
+	s.BaudRate = 0
+	if got := s.CharacterTime(); got != 0 {
+		t.Errorf("CharacterTime with zero baud rate = %v", got)
+	}
+	for d, want := range map[time.Duration]int{
+		-1:                   -1,
+		0:                    0,
+		1:                    1,
+		time.Millisecond:     1,
+		time.Millisecond + 1: 2,
+		3645834:              4,
+	} {
+		if got := gxcommon.WaitTimeOf(d); got != want {
+			t.Errorf("WaitTimeOf(%v) = %d, want %d", d, got, want)
+		}
+	}
+}
+
+func TestGetType(t *testing.T) {
+	tests := []struct {
+		got  gxcommon.DataType
+		want gxcommon.DataType
+	}{
+		{gxcommon.GetType[int8](), gxcommon.DataTypeInt8},
+		{gxcommon.GetType[float32](), gxcommon.DataTypeFloat32},
+		{gxcommon.GetType[float64](), gxcommon.DataTypeFloat64},
+		{gxcommon.GetType[bool](), gxcommon.DataTypeBool},
+		{gxcommon.GetType[int](), gxcommon.DataTypeInt},
+		{gxcommon.GetType[uint](), gxcommon.DataTypeUint},
+		{gxcommon.GetType[rune](), gxcommon.DataTypeInt32},
+		{gxcommon.GetType[struct{}](), gxcommon.DataTypeUnknown},
+	}
+	for _, tt := range tests {
+		if tt.got != tt.want {
+			t.Errorf("GetType = %s, want %s", tt.got, tt.want)
+		}
+	}
+}
+
+func TestRune(t *testing.T) {
+	for _, r := range []rune{'A', 'ä', '€', '😀'} {
+		b, err := gxcommon.ToBytes2(r, gxcommon.DataTypeRune, binary.BigEndian)
+		if err != nil || string(b) != string(r) {
+			t.Errorf("ToBytes2(%q) = % X, %v", r, b, err)
+		}
+		got, err := gxcommon.BytesToAny2(b, gxcommon.DataTypeRune, binary.BigEndian)
+		if err != nil || got != r {
+			t.Errorf("BytesToAny2(% X) = %v, %v, want %q", b, got, err, r)
+		}
+	}
+	if _, err := gxcommon.BytesToAny2([]byte{0xFF}, gxcommon.DataTypeRune, binary.BigEndian); !errors.Is(err, gxcommon.ErrInvalidArgument) {
+		t.Errorf("invalid UTF-8: %v", err)
+	}
+	if _, err := gxcommon.ToBytes2(int16(1), gxcommon.DataTypeRune, binary.BigEndian); !errors.Is(err, gxcommon.ErrInvalidArgument) {
+		t.Errorf("ToBytes2 of int16 as rune: %v", err)
+	}
+}
+
+func TestArray(t *testing.T) {
+	v := [3]uint16{1, 0x0203, 0xFFFF}
+	b, err := gxcommon.ToBytes(v, binary.LittleEndian)
+	if err != nil || gxcommon.ToHex(b) != "01 00 03 02 FF FF" {
+		t.Fatalf("ToBytes = % X, %v", b, err)
+	}
+	got, err := gxcommon.BytesToAny[[3]uint16](b, binary.LittleEndian)
+	if err != nil || got != v {
+		t.Errorf("BytesToAny = %v, %v, want %v", got, err, v)
+	}
+	if _, err = gxcommon.BytesToAny[[3]uint16](b[:5], binary.LittleEndian); err == nil {
+		t.Error("BytesToAny accepted a short buffer")
+	}
+	if _, err = gxcommon.BytesToAny[[]uint16](b[:5], binary.LittleEndian); !errors.Is(err, gxcommon.ErrInvalidArgument) {
+		t.Errorf("BytesToAny of odd length = %v, want ErrInvalidArgument", err)
+	}
+	if _, err = gxcommon.BytesToAny[any](b, binary.LittleEndian); err == nil {
+		t.Error("BytesToAny[any] succeeded")
+	}
+}
+
+type marshalHeader struct {
+	Version uint8
+	Flags   uint16 `gx:"order=little"`
+}
+
+type marshalRecord struct {
+	Header   marshalHeader
+	Serial   uint32 `gx:"bcd,len=4"`
+	Name     string `gx:"len=6"`
+	_        [1]byte
+	Enabled  bool `gx:"pad=1"`
+	Scale    float32
+	Values   []int16 `gx:"prefix=1"`
+	internal int
+	Skipped  int `gx:"-"`
+	Tail     []byte
+}
+
+func TestMarshal(t *testing.T) {
+	v := marshalRecord{
+		Header:  marshalHeader{Version: 1, Flags: 0x0203},
+		Serial:  12345678,
+		Name:    "Gurux",
+		Enabled: true,
+		Scale:   0.5,
+		Values:  []int16{-1, 2},
+		Skipped: 5,
+		Tail:    []byte{0xAA, 0xBB},
+	}
+	data, err := gxcommon.Marshal(&v, binary.BigEndian)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := "01 03 02 12 34 56 78 47 75 72 75 78 00 00 01 00 3F 00 00 00 02 FF FF 00 02 AA BB"
+	if got := gxcommon.ToHex(data); got != want {
+		t.Fatalf("Marshal = %s, want %s", got, want)
+	}
+	var got marshalRecord
+	if err = gxcommon.Unmarshal(data, &got, binary.BigEndian); err != nil {
+		t.Fatal(err)
+	}
+	v.Skipped = 0
+	if !reflect.DeepEqual(got, v) {
+		t.Errorf("Unmarshal = %+v, want %+v", got, v)
+	}
+	// Every truncation must fail with the name of the field.
+	for i := range len(data) - len(v.Tail) {
+		err = gxcommon.Unmarshal(data[:i], &got, binary.BigEndian)
+		if !errors.Is(err, gxcommon.ErrBufferTooSmall) {
+			t.Fatalf("Unmarshal of %d bytes = %v, want ErrBufferTooSmall", i, err)
+		}
+	}
+	if err = gxcommon.Unmarshal(data[:4], &got, binary.BigEndian); err.Error() != "buffer too small: Serial" {
+		t.Errorf("Unmarshal error = %v", err)
+	}
+}
+
+func TestMarshalErrors(t *testing.T) {
+	if _, err := gxcommon.Marshal(1, binary.BigEndian); !errors.Is(err, gxcommon.ErrInvalidArgument) {
+		t.Errorf("Marshal(int) = %v", err)
+	}
+	if _, err := gxcommon.Marshal(struct {
+		A string `gx:"len=2"`
+	}{"ABC"}, binary.BigEndian); !errors.Is(err, gxcommon.ErrArgumentOutOfRange) {
+		t.Errorf("Marshal of too long string = %v", err)
+	}
+	if _, err := gxcommon.Marshal(struct {
+		A int `gx:"bcd"`
+	}{}, binary.BigEndian); !errors.Is(err, gxcommon.ErrInvalidArgument) {
+		t.Errorf("Marshal of bcd without len = %v", err)
+	}
+	if _, err := gxcommon.Marshal(struct {
+		A uint8 `gx:"bcd,len=1"`
+	}{100}, binary.BigEndian); !errors.Is(err, gxcommon.ErrArgumentOutOfRange) {
+		t.Errorf("Marshal of too large BCD = %v", err)
+	}
+	var v struct {
+		A uint8 `gx:"bcd,len=1"`
+	}
+	if err := gxcommon.Unmarshal([]byte{0x1A}, &v, binary.BigEndian); !errors.Is(err, gxcommon.ErrInvalidArgument) {
+		t.Errorf("Unmarshal of invalid BCD = %v", err)
+	}
+	if err := gxcommon.Unmarshal([]byte{0x12}, v, binary.BigEndian); !errors.Is(err, gxcommon.ErrInvalidArgument) {
+		t.Errorf("Unmarshal to a struct value = %v", err)
+	}
+	// Elements that use no bytes must not loop forever.
+	var empty struct{ A []struct{} }
+	if err := gxcommon.Unmarshal([]byte{1}, &empty, binary.BigEndian); !errors.Is(err, gxcommon.ErrInvalidArgument) {
+		t.Errorf("Unmarshal of []struct{} = %v", err)
+	}
+	var zero struct{ A [][0]byte }
+	if err := gxcommon.Unmarshal([]byte{1}, &zero, binary.BigEndian); !errors.Is(err, gxcommon.ErrInvalidArgument) {
+		t.Errorf("Unmarshal of [][0]byte = %v", err)
+	}
+	var prefixed struct {
+		A []struct{} `gx:"prefix=1"`
+	}
+	if err := gxcommon.Unmarshal([]byte{2, 0}, &prefixed, binary.BigEndian); err != nil || len(prefixed.A) != 2 {
+		t.Errorf("Unmarshal of prefixed []struct{} = %v, %d", err, len(prefixed.A))
+	}
+}
+
+func TestGXByteBuffer(t *testing.T) {
+	for _, order := range []binary.ByteOrder{binary.BigEndian, binary.LittleEndian} {
+		bb := gxcommon.NewGXByteBuffer(0)
+		bb.SetByteOrder(order)
+		bb.SetUint8(0xFE)
+		bb.SetInt8(-2)
+		bb.SetBool(true)
+		bb.SetUint16(0xFEDC)
+		bb.SetInt16(-2)
+		bb.SetUint32(0xFEDCBA98)
+		bb.SetInt32(-2)
+		bb.SetUint64(math.MaxUint64)
+		bb.SetInt64(math.MinInt64)
+		bb.SetInt(-3)
+		bb.SetUint(3)
+		bb.SetFloat32(1.5)
+		bb.SetFloat64(math.Pi)
+		bb.SetRune('€')
+		bb.SetBytes([]byte{1, 2})
+		bb.SetString("AB")
+		if err := bb.Set([]uint16{1, 2}); err != nil {
+			t.Fatal(err)
+		}
+		check := func(got any, err error, want any) {
+			t.Helper()
+			if err != nil || !reflect.DeepEqual(got, want) {
+				t.Errorf("%s: got %v (%T), %v, want %v", order, got, got, err, want)
+			}
+		}
+		v1, err := bb.GetUint8()
+		check(v1, err, uint8(0xFE))
+		v2, err := bb.GetInt8()
+		check(v2, err, int8(-2))
+		v3, err := bb.GetBool()
+		check(v3, err, true)
+		v4, err := bb.GetUint16()
+		check(v4, err, uint16(0xFEDC))
+		v5, err := bb.GetInt16()
+		check(v5, err, int16(-2))
+		v6, err := bb.GetUint32()
+		check(v6, err, uint32(0xFEDCBA98))
+		v7, err := bb.GetInt32()
+		check(v7, err, int32(-2))
+		v8, err := bb.GetUint64()
+		check(v8, err, uint64(math.MaxUint64))
+		v9, err := bb.GetInt64()
+		check(v9, err, int64(math.MinInt64))
+		v10, err := bb.GetInt()
+		check(v10, err, -3)
+		v11, err := bb.GetUint()
+		check(v11, err, uint(3))
+		v12, err := bb.GetFloat32()
+		check(v12, err, float32(1.5))
+		v13, err := bb.GetFloat64()
+		check(v13, err, math.Pi)
+		v14, err := bb.GetRune()
+		check(v14, err, '€')
+		v15, err := bb.GetBytes(2)
+		check(v15, err, []byte{1, 2})
+		v16, err := bb.GetString(2)
+		check(v16, err, "AB")
+		v17, err := bb.Get(gxcommon.DataTypeUint16Slice, 2)
+		check(v17, err, []uint16{1, 2})
+		if bb.Available() != 0 {
+			t.Errorf("%d bytes left", bb.Available())
+		}
+		if _, err = bb.GetUint8(); !errors.Is(err, gxcommon.ErrBufferTooSmall) {
+			t.Errorf("GetUint8 at the end = %v", err)
+		}
+	}
+}
+
+func TestGXByteBufferCursor(t *testing.T) {
+	bb, err := gxcommon.NewGXByteBufferFromHex("7E A0 07 03 21 93 0F 01 7E")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bb.Compare([]byte{0x7E, 0xA0}) || bb.Position() != 2 {
+		t.Fatalf("Compare failed, position %d", bb.Position())
+	}
+	if bb.Compare([]byte{0x00}) || bb.Position() != 2 {
+		t.Fatalf("Compare of different data moved to %d", bb.Position())
+	}
+	if _, err = bb.GetUint64(); !errors.Is(err, gxcommon.ErrBufferTooSmall) || bb.Position() != 2 {
+		t.Fatalf("GetUint64 = %v, position %d", err, bb.Position())
+	}
+	if d, err := bb.Peek(2); err != nil || gxcommon.ToHex(d) != "07 03" || bb.Position() != 2 {
+		t.Fatalf("Peek = % X, %v", d, err)
+	}
+	sub, err := bb.SubBuffer(2, 3)
+	if err != nil || sub.String() != "07 03 21" {
+		t.Fatalf("SubBuffer = %v, %v", sub, err)
+	}
+	sub.SetUint8(0xFF)
+	if bb.String() != "7E A0 07 03 21 93 0F 01 7E" {
+		t.Errorf("appending to a view changed the buffer: %s", bb)
+	}
+	bb.Trim()
+	if bb.String() != "07 03 21 93 0F 01 7E" || bb.Position() != 0 {
+		t.Errorf("Trim = %s, position %d", bb, bb.Position())
+	}
+	if err = bb.SetPosition(8); !errors.Is(err, gxcommon.ErrArgumentOutOfRange) {
+		t.Errorf("SetPosition after the end = %v", err)
+	}
+	if _, err = gxcommon.NewGXByteBufferFromHex("7"); !errors.Is(err, gxcommon.ErrInvalidArgument) {
+		t.Errorf("invalid hex = %v", err)
+	}
+}
+
+func TestGXByteBufferSetAt(t *testing.T) {
+	bb := &gxcommon.GXByteBuffer{}
+	bb.SetUint8(0x7E)
+	bb.SetUint16(0)
+	bb.SetString("AB")
+	// Patch the length field after the payload is written.
+	if err := bb.SetUint16At(1, uint16(bb.Size()-3)); err != nil {
+		t.Fatal(err)
+	}
+	if err := bb.SetUint8At(0, 0x7F); err != nil {
+		t.Fatal(err)
+	}
+	if bb.String() != "7F 00 02 41 42" {
+		t.Fatalf("SetUint16At = %s", bb)
+	}
+	// Writes that pass the end extend the buffer.
+	if err := bb.SetUint32At(3, 0x01020304); err != nil || bb.String() != "7F 00 02 01 02 03 04" {
+		t.Fatalf("SetUint32At = %s, %v", bb, err)
+	}
+	if err := bb.SetUint64At(bb.Size(), 5); err != nil || bb.Size() != 15 {
+		t.Fatalf("SetUint64At at the end = %s, %v", bb, err)
+	}
+	bb.SetByteOrder(binary.LittleEndian)
+	if err := bb.SetAt(1, int16(-2)); err != nil || bb.String() != "7F FE FF 01 02 03 04 00 00 00 00 00 00 00 05" {
+		t.Fatalf("SetAt = %s, %v", bb, err)
+	}
+	if err := bb.SetBytesAt(13, []byte{6, 7, 8}); err != nil || gxcommon.ToHex(bb.Data()[13:]) != "06 07 08" {
+		t.Fatalf("SetBytesAt = %s, %v", bb, err)
+	}
+	for _, index := range []int{-1, bb.Size() + 1} {
+		if err := bb.SetUint8At(index, 0); !errors.Is(err, gxcommon.ErrArgumentOutOfRange) {
+			t.Errorf("SetUint8At(%d) = %v", index, err)
+		}
+		if err := bb.SetAt(index, uint32(0)); !errors.Is(err, gxcommon.ErrArgumentOutOfRange) {
+			t.Errorf("SetAt(%d) = %v", index, err)
+		}
+	}
+	if err := bb.SetAt(0, struct{}{}); err == nil {
+		t.Error("SetAt of an unsupported type succeeded")
+	}
+	if bb.Size() != 16 || bb.Position() != 0 {
+		t.Errorf("failed writes changed the buffer: %s, position %d", bb, bb.Position())
+	}
+}
+
+func TestGXByteBufferGetBCD(t *testing.T) {
+	bb, err := gxcommon.NewGXByteBufferFromHex("12 34 56 12 3D 99")
+	if err != nil {
+		t.Fatal(err)
+	}
+	v, err := bb.Get(gxcommon.DataTypeBCD, 3)
+	if err != nil || v != uint64(123456) {
+		t.Fatalf("Get(DataTypeBCD, 3) = %v, %v", v, err)
+	}
+	v, err = bb.Get(gxcommon.DataTypePackedDecimal, 2)
+	if err != nil || v != int64(-123) {
+		t.Fatalf("Get(DataTypePackedDecimal, 2) = %v, %v", v, err)
+	}
+	if _, err = bb.Get(gxcommon.DataTypeBCD, 2); !errors.Is(err, gxcommon.ErrBufferTooSmall) || bb.Position() != 5 {
+		t.Errorf("Get after the end = %v, position %d", err, bb.Position())
+	}
+	if _, err = bb.Get(gxcommon.DataTypePackedDecimal, 1); !errors.Is(err, gxcommon.ErrInvalidArgument) || bb.Position() != 5 {
+		t.Errorf("Get with a digit sign = %v, position %d", err, bb.Position())
+	}
+	if _, err = bb.Get(gxcommon.DataTypeBCD, 0); !errors.Is(err, gxcommon.ErrInvalidArgument) {
+		t.Errorf("Get(DataTypeBCD, 0) = %v", err)
+	}
+}
+
+func TestAllocations(t *testing.T) {
+	dst := make([]byte, 0, 64)
+	out := make([]byte, 0, 8)
+	var u16 uint16
+	var i32 int32
+	var u64 uint64
+	var f64 float64
+	for _, order := range []binary.ByteOrder{binary.BigEndian, binary.LittleEndian} {
+		allocs := testing.AllocsPerRun(100, func() {
+			b, _ := gxcommon.AppendBytes(dst[:0], uint16(0x1234), order)
+			b, _ = gxcommon.AppendBytes(b, int32(-5), order)
+			b, _ = gxcommon.AppendBytes(b, uint64(0x123456789), order)
+			b, _ = gxcommon.AppendBytes(b, 1.5, order)
+			_, _ = gxcommon.DecodeInto(b, &u16, order)
+			_, _ = gxcommon.DecodeInto(b[2:], &i32, order)
+			_, _ = gxcommon.DecodeInto(b[6:], &u64, order)
+			_, _ = gxcommon.DecodeInto(b[14:], &f64, order)
+			out = append(out[:0], b...)
+		})
+		if allocs != 0 {
+			t.Errorf("%s: %v allocations", order, allocs)
+		}
+		if u16 != 0x1234 || i32 != -5 || u64 != 0x123456789 || f64 != 1.5 {
+			t.Errorf("%s: decoded %X %d %X %v", order, u16, i32, u64, f64)
+		}
+	}
+	if allocs := testing.AllocsPerRun(100, func() { _ = gxcommon.ToHex(out) }); allocs != 1 {
+		t.Errorf("ToHex: %v allocations", allocs)
+	}
+}
+
+func BenchmarkAppendBytes(b *testing.B) {
+	dst := make([]byte, 0, 8)
+	b.ReportAllocs()
+	for b.Loop() {
+		dst, _ = gxcommon.AppendBytes(dst[:0], uint32(0x12345678), binary.BigEndian)
+	}
+}
+
+func BenchmarkDecodeInto(b *testing.B) {
+	data := []byte{1, 2, 3, 4, 5, 6, 7, 8}
+	var v uint64
+	b.ReportAllocs()
+	for b.Loop() {
+		_, _ = gxcommon.DecodeInto(data, &v, binary.LittleEndian)
+	}
+}
+
+func BenchmarkBytesToAny(b *testing.B) {
+	data := []byte{1, 2, 3, 4}
+	b.ReportAllocs()
+	for b.Loop() {
+		_, _ = gxcommon.BytesToAny[uint32](data, binary.BigEndian)
+	}
+}
+
+func BenchmarkToHex(b *testing.B) {
+	data := make([]byte, 256)
+	b.ReportAllocs()
+	for b.Loop() {
+		_ = gxcommon.ToHex(data)
+	}
+}
@@ -0,0 +1,136 @@
+package mediatest
+
+// --------------------------------------------------------------------------
+//
+//	Gurux Ltd
+//
+// Filename:        $HeadURL$
+//
+// Version:         $Revision$,
+//
+//	$Date$
+//	$Author$
+//
+// # Copyright (c) Gurux Ltd
+//
+// ---------------------------------------------------------------------------
+//
+//	DESCRIPTION
+//
+// This file is a part of Gurux Device Framework.
+//
+// Gurux Device Framework is Open Source software; you can redistribute it
+// and/or modify it under the terms of the GNU General Public License
+// as published by the Free Software Foundation; version 2 of the License.
+// Gurux Device Framework is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.
+// See the GNU General Public License for more details.
+//
+// More information of Gurux products: https://www.gurux.org
+//
+// This code is licensed under the GNU General Public License v2.
+// Full text may be retrieved at http://www.gnu.org/licenses/gpl-2.0.txt
+// ---------------------------------------------------------------------------
+
+import (
+	"slices"
+	"sync"
+	"time"
+
+	"github.com/Gurux/gxcommon-go"
+)
+
+// Collector collects the received data and errors of a media in tests.
+//
+// Collector is safe for concurrent use, so it can be used with medias that
+// raise events from other goroutines.
+type Collector struct {
+	mu       sync.Mutex
+	received [][]byte
+	times    []time.Time
+	errors   []error
+	// signal is notified without blocking when an event is collected.
+	signal chan struct{}
+}
+
+// NewCollector returns a Collector for the events of media. It replaces
+// the received and error handlers of media.
+func NewCollector(media gxcommon.IGXMedia) *Collector {
+	ret := &Collector{signal: make(chan struct{}, 1)}
+	media.SetOnReceived(func(_ gxcommon.IGXMedia, e gxcommon.ReceiveEventArgs) {
+		ret.mu.Lock()
+		ret.received = append(ret.received, e.Data())
+		ret.times = append(ret.times, time.Now())
+		ret.mu.Unlock()
+		ret.notify()
+	})
+	media.SetOnError(func(_ gxcommon.IGXMedia, err error) {
+		ret.mu.Lock()
+		ret.errors = append(ret.errors, err)
+		ret.mu.Unlock()
+		ret.notify()
+	})
+	return ret
+}
+
+// notify wakes up a waiting Wait call.
+func (c *Collector) notify() {
+	select {
+	case c.signal <- struct{}{}:
+	default:
+	}
+}
+
+// Received returns the received data.
+func (c *Collector) Received() [][]byte {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return slices.Clone(c.received)
+}
+
+// ReceivedStrings returns the received data as strings.
+func (c *Collector) ReceivedStrings() []string {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	ret := make([]string, len(c.received))
+	for i, it := range c.received {
+		ret[i] = string(it)
+	}
+	return ret
+}
+
+// Times returns the times when the data returned by Received was
+// received.
+func (c *Collector) Times() []time.Time {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return slices.Clone(c.times)
+}
+
+// Errors returns the errors.
+func (c *Collector) Errors() []error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return slices.Clone(c.errors)
+}
+
+// Wait waits until at least count data has been received. It returns false
+// if timeout elapses first.
+func (c *Collector) Wait(count int, timeout time.Duration) bool {
+	t := time.NewTimer(timeout)
+	defer t.Stop()
+	for {
+		c.mu.Lock()
+		n := len(c.received)
+		c.mu.Unlock()
+		if n >= count {
+			return true
+		}
+		select {
+		case <-c.signal:
+		case <-t.C:
+			return false
+		}
+	}
+}
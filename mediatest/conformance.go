@@ -0,0 +1,570 @@
+package mediatest
+
+// --------------------------------------------------------------------------
+//
+//	Gurux Ltd
+//
+// Filename:        $HeadURL$
+//
+// Version:         $Revision$,
+//
+//	$Date$
+//	$Author$
+//
+// # Copyright (c) Gurux Ltd
+//
+// ---------------------------------------------------------------------------
+//
+//	DESCRIPTION
+//
+// This file is a part of Gurux Device Framework.
+//
+// Gurux Device Framework is Open Source software; you can redistribute it
+// and/or modify it under the terms of the GNU General Public License
+// as published by the Free Software Foundation; version 2 of the License.
+// Gurux Device Framework is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.
+// See the GNU General Public License for more details.
+//
+// More information of Gurux products: https://www.gurux.org
+//
+// This code is licensed under the GNU General Public License v2.
+// Full text may be retrieved at http://www.gnu.org/licenses/gpl-2.0.txt
+// ---------------------------------------------------------------------------
+
+import (
+	"bytes"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/Gurux/gxcommon-go"
+)
+
+// waitTime is the maximum time in milliseconds to wait for sent data to
+// arrive.
+const waitTime = 5000
+
+// Factory returns two connected medias that are not open yet. Data sent
+// with a must be received by b and data sent with b must be received by a.
+//
+// Factory is called once for every test and it should register cleanup of
+// the medias and the connection with t.Cleanup.
+type Factory func(t *testing.T) (a, b gxcommon.IGXMedia)
+
+// RunConformance runs the IGXMedia conformance tests as subtests of t.
+//
+// The tests send only 7-bit data, so also medias that transfer 7-bit
+// characters, such as a serial line with a parity bit, can be tested.
+func RunConformance(t *testing.T, factory Factory) {
+	tests := []struct {
+		name string
+		fn   func(t *testing.T, factory Factory)
+	}{
+		{"MediaState", testMediaState},
+		{"Validate", testValidate},
+		{"SendReceived", testSendReceived},
+		{"ByteCounters", testByteCounters},
+		{"Trace", testTrace},
+		{"Synchronous", testSynchronous},
+		{"ReceiveEop", testReceiveEop},
+		{"ReceiveCount", testReceiveCount},
+		{"ReceivePeek", testReceivePeek},
+		{"ReceiveAllData", testReceiveAllData},
+		{"ReceiveTimeout", testReceiveTimeout},
+		{"ResetSynchronousBuffer", testResetSynchronousBuffer},
+		{"Eop", testEop},
+		{"Closed", testClosed},
+		{"Settings", testSettings},
+		{"Copy", testCopy},
+	}
+	for _, it := range tests {
+		t.Run(it.name, func(t *testing.T) {
+			it.fn(t, factory)
+		})
+	}
+}
+
+// open returns a new opened pair of medias that are closed when the test
+// ends.
+func open(t *testing.T, factory Factory) (gxcommon.IGXMedia, gxcommon.IGXMedia) {
+	t.Helper()
+	a, b := factory(t)
+	for _, it := range []gxcommon.IGXMedia{a, b} {
+		if err := it.Open(); err != nil {
+			t.Fatalf("Open() failed: %v", err)
+		}
+		m := it
+		t.Cleanup(func() {
+			_ = m.Close()
+		})
+	}
+	return a, b
+}
+
+// send sends data and fails the test on error.
+func send(t *testing.T, media gxcommon.IGXMedia, data []byte) {
+	t.Helper()
+	if err := media.Send(data, ""); err != nil {
+		t.Fatalf("Send() failed: %v", err)
+	}
+}
+
+// receive reads a reply using args and fails the test if it is not
+// received.
+func receive(t *testing.T, media gxcommon.IGXMedia, args *gxcommon.ReceiveParameters) []byte {
+	t.Helper()
+	ret, err := media.Receive(args)
+	if err != nil {
+		t.Fatalf("Receive() failed: %v", err)
+	}
+	if !ret {
+		t.Fatalf("Receive() timed out")
+	}
+	reply, ok := args.Reply.([]byte)
+	if !ok {
+		t.Fatalf("Receive() reply type is %T, want []byte", args.Reply)
+	}
+	return reply
+}
+
+// newArgs returns receive parameters for a byte slice reply.
+func newArgs() *gxcommon.ReceiveParameters {
+	ret := gxcommon.NewReceiveParameters[[]byte]()
+	ret.WaitTime = waitTime
+	return ret
+}
+
+// waitFor polls cond until it returns true or the wait time elapses.
+func waitFor(t *testing.T, what string, cond func() bool) {
+	t.Helper()
+	deadline := time.Now().Add(waitTime * time.Millisecond)
+	for !cond() {
+		if time.Now().After(deadline) {
+			t.Fatalf("timeout waiting for %s", what)
+		}
+		time.Sleep(time.Millisecond)
+	}
+}
+
+func testMediaState(t *testing.T, factory Factory) {
+	a, _ := factory(t)
+	var mu sync.Mutex
+	var states []gxcommon.MediaState
+	a.SetOnMediaStateChange(func(_ gxcommon.IGXMedia, e gxcommon.MediaStateEventArgs) {
+		mu.Lock()
+		states = append(states, e.State())
+		mu.Unlock()
+	})
+	check := func(want ...gxcommon.MediaState) {
+		t.Helper()
+		mu.Lock()
+		defer mu.Unlock()
+		if len(states) != len(want) {
+			t.Fatalf("state events are %v, want %v", states, want)
+		}
+		for i := range want {
+			if states[i] != want[i] {
+				t.Fatalf("state events are %v, want %v", states, want)
+			}
+		}
+		states = nil
+	}
+	if a.IsOpen() {
+		t.Fatal("IsOpen() is true before Open()")
+	}
+	if err := a.Open(); err != nil {
+		t.Fatalf("Open() failed: %v", err)
+	}
+	t.Cleanup(func() {
+		_ = a.Close()
+	})
+	check(gxcommon.MediaStateOpening, gxcommon.MediaStateOpen)
+	if !a.IsOpen() {
+		t.Fatal("IsOpen() is false after Open()")
+	}
+	if err := a.Close(); err != nil {
+		t.Fatalf("Close() failed: %v", err)
+	}
+	check(gxcommon.MediaStateClosing, gxcommon.MediaStateClosed)
+	if a.IsOpen() {
+		t.Fatal("IsOpen() is true after Close()")
+	}
+	// The media can be opened again after it is closed.
+	if err := a.Open(); err != nil {
+		t.Fatalf("Open() after Close() failed: %v", err)
+	}
+	check(gxcommon.MediaStateOpening, gxcommon.MediaStateOpen)
+}
+
+func testValidate(t *testing.T, factory Factory) {
+	a, b := factory(t)
+	for _, it := range []gxcommon.IGXMedia{a, b} {
+		if err := it.Validate(); err != nil {
+			t.Fatalf("Validate() failed: %v", err)
+		}
+	}
+}
+
+func testSendReceived(t *testing.T, factory Factory) {
+	a, b := open(t, factory)
+	var mu sync.Mutex
+	var received []byte
+	b.SetOnReceived(func(_ gxcommon.IGXMedia, e gxcommon.ReceiveEventArgs) {
+		mu.Lock()
+		received = append(received, e.Data()...)
+		mu.Unlock()
+	})
+	data := []byte{0x7E, 0x00, 0x07, 0x03, 0x21, 0x13, 0x0F, 0x7F, 0x7E}
+	send(t, a, data)
+	waitFor(t, "received data", func() bool {
+		mu.Lock()
+		defer mu.Unlock()
+		return len(received) >= len(data)
+	})
+	mu.Lock()
+	defer mu.Unlock()
+	if !bytes.Equal(received, data) {
+		t.Fatalf("received %X, want %X", received, data)
+	}
+}
+
+func testByteCounters(t *testing.T, factory Factory) {
+	a, b := open(t, factory)
+	restore := b.GetSynchronous()
+	defer restore()
+	send(t, a, []byte("12345"))
+	send(t, a, []byte("678"))
+	waitFor(t, "received bytes", func() bool {
+		return b.GetBytesReceived() == 8
+	})
+	if ret := a.GetBytesSent(); ret != 8 {
+		t.Fatalf("GetBytesSent() = %d, want 8", ret)
+	}
+	a.ResetByteCounters()
+	b.ResetByteCounters()
+	if a.GetBytesSent() != 0 || b.GetBytesReceived() != 0 {
+		t.Fatal("ResetByteCounters() did not reset the counters")
+	}
+}
+
+func testTrace(t *testing.T, factory Factory) {
+	a, b := open(t, factory)
+	for _, it := range gxcommon.AllTraceLevel() {
+		if err := a.SetTrace(it); err != nil {
+			t.Fatalf("SetTrace(%v) failed: %v", it, err)
+		}
+		if ret := a.GetTrace(); ret != it {
+			t.Fatalf("GetTrace() = %v, want %v", ret, it)
+		}
+	}
+	var mu sync.Mutex
+	var sent []byte
+	a.SetOnTrace(func(_ gxcommon.IGXMedia, e gxcommon.TraceEventArgs) {
+		if e.Type() == gxcommon.TraceTypesSent {
+			mu.Lock()
+			sent = append(sent, e.Data().([]byte)...)
+			mu.Unlock()
+		}
+	})
+	restore := b.GetSynchronous()
+	defer restore()
+	if err := a.SetTrace(gxcommon.TraceLevelOff); err != nil {
+		t.Fatalf("SetTrace() failed: %v", err)
+	}
+	send(t, a, []byte{1})
+	if err := a.SetTrace(gxcommon.TraceLevelVerbose); err != nil {
+		t.Fatalf("SetTrace() failed: %v", err)
+	}
+	send(t, a, []byte{2, 3})
+	waitFor(t, "sent trace", func() bool {
+		mu.Lock()
+		defer mu.Unlock()
+		return len(sent) >= 2
+	})
+	mu.Lock()
+	defer mu.Unlock()
+	if !bytes.Equal(sent, []byte{2, 3}) {
+		t.Fatalf("traced %X, want 02 03", sent)
+	}
+}
+
+func testSynchronous(t *testing.T, factory Factory) {
+	a, b := open(t, factory)
+	if b.IsSynchronous() {
+		t.Fatal("IsSynchronous() is true by default")
+	}
+	restore := b.GetSynchronous()
+	if !b.IsSynchronous() {
+		t.Fatal("IsSynchronous() is false after GetSynchronous()")
+	}
+	received := make(chan struct{}, 1)
+	b.SetOnReceived(func(gxcommon.IGXMedia, gxcommon.ReceiveEventArgs) {
+		select {
+		case received <- struct{}{}:
+		default:
+		}
+	})
+	send(t, a, []byte("ABC"))
+	args := newArgs()
+	args.Count = 3
+	if ret := receive(t, b, args); string(ret) != "ABC" {
+		t.Fatalf("Receive() = %q, want \"ABC\"", ret)
+	}
+	select {
+	case <-received:
+		t.Fatal("received event raised in synchronous mode")
+	default:
+	}
+	restore()
+	if b.IsSynchronous() {
+		t.Fatal("IsSynchronous() is true after restore")
+	}
+}
+
+func testReceiveEop(t *testing.T, factory Factory) {
+	a, b := open(t, factory)
+	restore := b.GetSynchronous()
+	defer restore()
+	send(t, a, []byte("AB\nCD"))
+	send(t, a, []byte("E\n"))
+	for _, want := range []string{"AB\n", "CDE\n"} {
+		args := newArgs()
+		args.EOP = byte('\n')
+		if ret := receive(t, b, args); string(ret) != want {
+			t.Fatalf("Receive() = %q, want %q", ret, want)
+		}
+	}
+	// Multi-byte EOP.
+	send(t, a, []byte("F\r\nG\r\n"))
+	args := newArgs()
+	args.EOP = "\r\n"
+	if ret := receive(t, b, args); string(ret) != "F\r\n" {
+		t.Fatalf("Receive() = %q, want \"F\\r\\n\"", ret)
+	}
+}
+
+func testReceiveCount(t *testing.T, factory Factory) {
+	a, b := open(t, factory)
+	restore := b.GetSynchronous()
+	defer restore()
+	send(t, a, []byte{1, 2})
+	send(t, a, []byte{3, 4, 5})
+	args := newArgs()
+	args.Count = 4
+	if ret := receive(t, b, args); !bytes.Equal(ret, []byte{1, 2, 3, 4}) {
+		t.Fatalf("Receive() = %X, want 01020304", ret)
+	}
+	args = newArgs()
+	args.Count = 1
+	if ret := receive(t, b, args); !bytes.Equal(ret, []byte{5}) {
+		t.Fatalf("Receive() = %X, want 05", ret)
+	}
+}
+
+func testReceivePeek(t *testing.T, factory Factory) {
+	a, b := open(t, factory)
+	restore := b.GetSynchronous()
+	defer restore()
+	send(t, a, []byte("XYZ"))
+	for range 2 {
+		args := newArgs()
+		args.Count = 2
+		args.Peek = true
+		if ret := receive(t, b, args); string(ret) != "XY" {
+			t.Fatalf("Receive() with Peek = %q, want \"XY\"", ret)
+		}
+	}
+	args := newArgs()
+	args.Count = 3
+	if ret := receive(t, b, args); string(ret) != "XYZ" {
+		t.Fatalf("Receive() after Peek = %q, want \"XYZ\"", ret)
+	}
+}
+
+func testReceiveAllData(t *testing.T, factory Factory) {
+	a, b := open(t, factory)
+	restore := b.GetSynchronous()
+	defer restore()
+	send(t, a, []byte("AB\nCD"))
+	args := newArgs()
+	args.EOP = byte('\n')
+	args.AllData = true
+	waitFor(t, "received bytes", func() bool {
+		return b.GetBytesReceived() == 5
+	})
+	if ret := receive(t, b, args); string(ret) != "AB\nCD" {
+		t.Fatalf("Receive() with AllData = %q, want \"AB\\nCD\"", ret)
+	}
+}
+
+func testReceiveTimeout(t *testing.T, factory Factory) {
+	a, b := open(t, factory)
+	restore := b.GetSynchronous()
+	defer restore()
+	send(t, a, []byte("AB"))
+	waitFor(t, "received bytes", func() bool {
+		return b.GetBytesReceived() == 2
+	})
+	args := newArgs()
+	args.EOP = byte('\n')
+	args.WaitTime = 50
+	start := time.Now()
+	ret, err := b.Receive(args)
+	if err != nil {
+		t.Fatalf("Receive() failed: %v", err)
+	}
+	if ret {
+		t.Fatal("Receive() succeeded without EOP")
+	}
+	if elapsed := time.Since(start); elapsed < 40*time.Millisecond {
+		t.Fatalf("Receive() returned after %v, want at least 50ms", elapsed)
+	}
+	// Timed out data is left to the buffer.
+	send(t, a, []byte("\n"))
+	args = newArgs()
+	args.EOP = byte('\n')
+	if ret := receive(t, b, args); string(ret) != "AB\n" {
+		t.Fatalf("Receive() = %q, want \"AB\\n\"", ret)
+	}
+}
+
+func testResetSynchronousBuffer(t *testing.T, factory Factory) {
+	a, b := open(t, factory)
+	restore := b.GetSynchronous()
+	defer restore()
+	send(t, a, []byte("OLD"))
+	waitFor(t, "received bytes", func() bool {
+		return b.GetBytesReceived() == 3
+	})
+	b.ResetSynchronousBuffer()
+	send(t, a, []byte("NEW"))
+	args := newArgs()
+	args.Count = 3
+	if ret := receive(t, b, args); string(ret) != "NEW" {
+		t.Fatalf("Receive() after reset = %q, want \"NEW\"", ret)
+	}
+}
+
+func testEop(t *testing.T, factory Factory) {
+	a, _ := factory(t)
+	for _, it := range []any{byte(0x7E), "\r\n", []byte{0x0D, 0x0A}} {
+		a.SetEop(it)
+		if ret := a.GetEop(); !bytes.Equal(toBytes(ret), toBytes(it)) {
+			t.Fatalf("GetEop() = %v, want %v", ret, it)
+		}
+	}
+	a.SetEop(nil)
+	if ret := a.GetEop(); ret != nil {
+		t.Fatalf("GetEop() = %v, want nil", ret)
+	}
+}
+
+// toBytes converts an end-of-packet marker to bytes for comparison.
+func toBytes(value any) []byte {
+	switch x := value.(type) {
+	case byte:
+		return []byte{x}
+	case string:
+		return []byte(x)
+	case []byte:
+		return x
+	}
+	return nil
+}
+
+func testClosed(t *testing.T, factory Factory) {
+	a, b := open(t, factory)
+	restore := b.GetSynchronous()
+	defer restore()
+	closed := make(chan struct{})
+	var once sync.Once
+	b.SetOnMediaStateChange(func(_ gxcommon.IGXMedia, e gxcommon.MediaStateEventArgs) {
+		if e.State() == gxcommon.MediaStateClosed {
+			once.Do(func() { close(closed) })
+		}
+	})
+	// A pending receive is released when the media is closed. Receive
+	// must return ErrConnectionClosed also if it starts after Close.
+	done := make(chan error, 1)
+	go func() {
+		args := gxcommon.NewReceiveParameters[[]byte]()
+		args.EOP = byte('\n')
+		_, err := b.Receive(args)
+		done <- err
+	}()
+	if err := b.Close(); err != nil {
+		t.Fatalf("Close() failed: %v", err)
+	}
+	select {
+	case <-closed:
+	case <-time.After(waitTime * time.Millisecond):
+		t.Fatal("Close() did not raise MediaStateClosed")
+	}
+	select {
+	case err := <-done:
+		if !errors.Is(err, gxcommon.ErrConnectionClosed) {
+			t.Fatalf("pending Receive() returned %v, want ErrConnectionClosed", err)
+		}
+	case <-time.After(waitTime * time.Millisecond):
+		t.Fatal("pending Receive() was not released by Close()")
+	}
+	args := newArgs()
+	args.Count = 1
+	if _, err := b.Receive(args); !errors.Is(err, gxcommon.ErrConnectionClosed) {
+		t.Fatalf("Receive() after Close() returned %v, want ErrConnectionClosed", err)
+	}
+	if err := a.Close(); err != nil {
+		t.Fatalf("Close() failed: %v", err)
+	}
+	if err := a.Send([]byte{1}, ""); !errors.Is(err, gxcommon.ErrConnectionClosed) {
+		t.Fatalf("Send() after Close() returned %v, want ErrConnectionClosed", err)
+	}
+	// Closing a closed media is allowed.
+	if err := a.Close(); err != nil {
+		t.Fatalf("second Close() failed: %v", err)
+	}
+}
+
+func testSettings(t *testing.T, factory Factory) {
+	a, b := factory(t)
+	if a.GetMediaType() == "" {
+		t.Fatal("GetMediaType() is empty")
+	}
+	if a.GetName() == "" {
+		t.Fatal("GetName() is empty")
+	}
+	settings := a.GetSettings()
+	if err := b.SetSettings(settings); err != nil {
+		t.Fatalf("SetSettings() failed: %v", err)
+	}
+	if ret := b.GetSettings(); ret != settings {
+		t.Fatalf("GetSettings() = %q after SetSettings(%q)", ret, settings)
+	}
+}
+
+func testCopy(t *testing.T, factory Factory) {
+	a, _ := factory(t)
+	c, _ := factory(t)
+	if err := a.SetTrace(gxcommon.TraceLevelInfo); err != nil {
+		t.Fatalf("SetTrace() failed: %v", err)
+	}
+	a.SetEop("\r\n")
+	if err := a.Copy(c); err != nil {
+		t.Fatalf("Copy() failed: %v", err)
+	}
+	if c.GetMediaType() != a.GetMediaType() {
+		t.Fatalf("GetMediaType() = %q after Copy(), want %q", c.GetMediaType(), a.GetMediaType())
+	}
+	if c.GetSettings() != a.GetSettings() {
+		t.Fatalf("GetSettings() = %q after Copy(), want %q", c.GetSettings(), a.GetSettings())
+	}
+	if ret := c.GetTrace(); ret != gxcommon.TraceLevelInfo {
+		t.Fatalf("GetTrace() = %v after Copy(), want %v", ret, gxcommon.TraceLevelInfo)
+	}
+	if ret := c.GetEop(); !bytes.Equal(toBytes(ret), []byte("\r\n")) {
+		t.Fatalf("GetEop() = %v after Copy(), want %q", ret, "\r\n")
+	}
+}
@@ -0,0 +1,47 @@
+// Package mediatest provides a conformance test suite for IGXMedia
+// implementations.
+//
+// A media package runs the suite from its own tests:
+//
+//	func TestConformance(t *testing.T) {
+//		mediatest.RunConformance(t, func(t *testing.T) (gxcommon.IGXMedia, gxcommon.IGXMedia) {
+//			a, b := newConnectedPair(t)
+//			return a, b
+//		})
+//	}
+//
+// Collector collects the events of a media in the tests of media packages.
+package mediatest
+
+// --------------------------------------------------------------------------
+//
+//	Gurux Ltd
+//
+// Filename:        $HeadURL$
+//
+// Version:         $Revision$,
+//
+//	$Date$
+//	$Author$
+//
+// # Copyright (c) Gurux Ltd
+//
+// ---------------------------------------------------------------------------
+//
+//	DESCRIPTION
+//
+// This file is a part of Gurux Device Framework.
+//
+// Gurux Device Framework is Open Source software; you can redistribute it
+// and/or modify it under the terms of the GNU General Public License
+// as published by the Free Software Foundation; version 2 of the License.
+// Gurux Device Framework is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.
+// See the GNU General Public License for more details.
+//
+// More information of Gurux products: https://www.gurux.org
+//
+// This code is licensed under the GNU General Public License v2.
+// Full text may be retrieved at http://www.gnu.org/licenses/gpl-2.0.txt
+// ---------------------------------------------------------------------------
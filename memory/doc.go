@@ -0,0 +1,44 @@
+// Package memory provides in-memory IGXMedia implementations.
+//
+// The medias are meant for testing code that uses IGXMedia without
+// hardware. NewLoopback returns a media that receives everything it sends and
+// NewPair returns two connected medias. Both implement the whole IGXMedia
+// contract, including trace events, media state events, byte counters and
+// synchronous mode.
+//
+// Importing the package registers MediaType with gxcommon.RegisterMedia.
+// gxcommon.NewMedia creates a loopback media for it.
+package memory
+
+// --------------------------------------------------------------------------
+//
+//	Gurux Ltd
+//
+// Filename:        $HeadURL$
+//
+// Version:         $Revision$,
+//
+//	$Date$
+//	$Author$
+//
+// # Copyright (c) Gurux Ltd
+//
+// ---------------------------------------------------------------------------
+//
+//	DESCRIPTION
+//
+// This file is a part of Gurux Device Framework.
+//
+// Gurux Device Framework is Open Source software; you can redistribute it
+// and/or modify it under the terms of the GNU General Public License
+// as published by the Free Software Foundation; version 2 of the License.
+// Gurux Device Framework is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.
+// See the GNU General Public License for more details.
+//
+// More information of Gurux products: https://www.gurux.org
+//
+// This code is licensed under the GNU General Public License v2.
+// Full text may be retrieved at http://www.gnu.org/licenses/gpl-2.0.txt
+// ---------------------------------------------------------------------------
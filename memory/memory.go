@@ -0,0 +1,247 @@
+package memory
+
+// --------------------------------------------------------------------------
+//
+//	Gurux Ltd
+//
+// Filename:        $HeadURL$
+//
+// Version:         $Revision$,
+//
+//	$Date$
+//	$Author$
+//
+// # Copyright (c) Gurux Ltd
+//
+// ---------------------------------------------------------------------------
+//
+//	DESCRIPTION
+//
+// This file is a part of Gurux Device Framework.
+//
+// Gurux Device Framework is Open Source software; you can redistribute it
+// and/or modify it under the terms of the GNU General Public License
+// as published by the Free Software Foundation; version 2 of the License.
+// Gurux Device Framework is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.
+// See the GNU General Public License for more details.
+//
+// More information of Gurux products: https://www.gurux.org
+//
+// This code is licensed under the GNU General Public License v2.
+// Full text may be retrieved at http://www.gnu.org/licenses/gpl-2.0.txt
+// ---------------------------------------------------------------------------
+
+import (
+	"bytes"
+	"encoding/binary"
+	"encoding/xml"
+	"strings"
+	"sync"
+
+	"github.com/Gurux/gxcommon-go"
+)
+
+// MediaType is the media type of in-memory medias.
+const MediaType = "Memory"
+
+func init() {
+	gxcommon.RegisterMedia(MediaType, func() gxcommon.IGXMedia {
+		return NewLoopback()
+	})
+}
+
+// Media is an in-memory IGXMedia.
+//
+// Data sent with Send is delivered to the peer media immediately, before
+// Send returns. A loopback media is its own peer. Data sent while the peer
+// is closed is discarded.
+type Media struct {
+	gxcommon.GXMediaBase
+	mu   sync.Mutex
+	name string
+	// state is the media state. The zero value means closed.
+	state gxcommon.MediaState
+	peer  *Media
+}
+
+// settings is the serialized form of the media settings.
+type settings struct {
+	Name      string `xml:"Name"`
+	ByteOrder string `xml:"ByteOrder"`
+}
+
+// NewLoopback returns a media that receives everything it sends.
+func NewLoopback() *Media {
+	ret := &Media{name: "Loopback"}
+	ret.peer = ret
+	return ret
+}
+
+// NewPair returns two connected medias. Data sent with a is received by b
+// and data sent with b is received by a.
+func NewPair() (*Media, *Media) {
+	a := &Media{name: "A"}
+	b := &Media{name: "B"}
+	a.peer = b
+	b.peer = a
+	return a, b
+}
+
+// Peer returns the media that receives the data sent with m.
+func (m *Media) Peer() *Media {
+	return m.peer
+}
+
+// Send transmits data to the peer media.
+//
+// data is converted to bytes with gxcommon.ToBytes using the byte order of
+// the media. Send returns ErrConnectionClosed if the media is not open.
+func (m *Media) Send(data any, receiver string) error {
+	if !m.IsOpen() {
+		return gxcommon.ErrConnectionClosed
+	}
+	b, err := gxcommon.ToBytes(data, m.GetByteOrder())
+	if err != nil {
+		return err
+	}
+	m.NotifySent(m, b, receiver)
+	if m.peer.IsOpen() {
+		m.peer.NotifyReceived(m.peer, bytes.Clone(b), m.GetName())
+	}
+	return nil
+}
+
+// Copy copies the media settings, byte order, trace level and end-of-packet
+// marker to target.
+//
+// It returns ErrInvalidArgument if target is not an in-memory media.
+func (m *Media) Copy(target gxcommon.IGXMedia) error {
+	t, ok := target.(*Media)
+	if !ok {
+		return gxcommon.ErrInvalidArgumentError("target")
+	}
+	if err := t.SetSettings(m.GetSettings()); err != nil {
+		return err
+	}
+	if err := t.SetTrace(m.GetTrace()); err != nil {
+		return err
+	}
+	t.SetByteOrder(m.GetByteOrder())
+	t.SetEop(m.GetEop())
+	return nil
+}
+
+// GetName returns the media name.
+func (m *Media) GetName() string {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.name
+}
+
+// SetName sets the media name.
+func (m *Media) SetName(value string) {
+	m.mu.Lock()
+	m.name = value
+	m.mu.Unlock()
+}
+
+// Open opens the media. It does nothing if the media is open or being
+// opened.
+func (m *Media) Open() error {
+	if err := m.Validate(); err != nil {
+		return err
+	}
+	m.mu.Lock()
+	if m.state == gxcommon.MediaStateOpen || m.state == gxcommon.MediaStateOpening {
+		m.mu.Unlock()
+		return nil
+	}
+	m.state = gxcommon.MediaStateOpening
+	m.mu.Unlock()
+	m.NotifyMediaStateChange(m, gxcommon.MediaStateOpening)
+	m.mu.Lock()
+	m.state = gxcommon.MediaStateOpen
+	m.mu.Unlock()
+	m.NotifyMediaStateChange(m, gxcommon.MediaStateOpen)
+	return nil
+}
+
+// IsOpen reports whether the media is open. The media is open also while
+// it is closing.
+func (m *Media) IsOpen() bool {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.state == gxcommon.MediaStateOpen || m.state == gxcommon.MediaStateClosing
+}
+
+// Close closes the media. It does nothing if the media is not open or is
+// being closed.
+func (m *Media) Close() error {
+	m.mu.Lock()
+	if m.state != gxcommon.MediaStateOpen {
+		m.mu.Unlock()
+		return nil
+	}
+	m.state = gxcommon.MediaStateClosing
+	m.mu.Unlock()
+	m.NotifyMediaStateChange(m, gxcommon.MediaStateClosing)
+	m.mu.Lock()
+	m.state = gxcommon.MediaStateClosed
+	m.mu.Unlock()
+	m.NotifyMediaStateChange(m, gxcommon.MediaStateClosed)
+	return nil
+}
+
+// GetMediaType returns MediaType.
+func (m *Media) GetMediaType() string {
+	return MediaType
+}
+
+// GetSettings returns media settings in serialized form.
+//
+// The byte order is written only if it is not big-endian.
+func (m *Media) GetSettings() string {
+	var sb strings.Builder
+	sb.WriteString("<Name>")
+	_ = xml.EscapeText(&sb, []byte(m.GetName()))
+	sb.WriteString("</Name>")
+	if order := m.GetByteOrder(); order != binary.BigEndian {
+		sb.WriteString("<ByteOrder>")
+		sb.WriteString(order.String())
+		sb.WriteString("</ByteOrder>")
+	}
+	return sb.String()
+}
+
+// SetSettings applies serialized media settings.
+//
+// Settings that are not present in value are left unchanged. ByteOrder is
+// a name accepted by gxcommon.ByteOrderParse.
+func (m *Media) SetSettings(value string) error {
+	s := settings{Name: m.GetName()}
+	if err := xml.Unmarshal([]byte("<Settings>"+value+"</Settings>"), &s); err != nil {
+		return gxcommon.ErrInvalidArgumentError("settings")
+	}
+	order := m.GetByteOrder()
+	if s.ByteOrder != "" {
+		var err error
+		if order, err = gxcommon.ByteOrderParse(s.ByteOrder); err != nil {
+			return err
+		}
+	}
+	m.SetName(s.Name)
+	m.SetByteOrder(order)
+	return nil
+}
+
+// Validate validates media settings.
+//
+// It returns ErrInvalidArgument if the media name is empty.
+func (m *Media) Validate() error {
+	if m.GetName() == "" {
+		return gxcommon.ErrInvalidArgumentError("Name")
+	}
+	return nil
+}
@@ -0,0 +1,52 @@
+// Package memory_test holds examples for the memory package.
+package memory_test
+
+import (
+	"fmt"
+
+	"github.com/Gurux/gxcommon-go"
+	"github.com/Gurux/gxcommon-go/memory"
+)
+
+// ExampleNewPair sends data from one media of a pair and reads it
+// synchronously from the other.
+func ExampleNewPair() {
+	a, b := memory.NewPair()
+	_ = a.Open()
+	_ = b.Open()
+	defer a.Close()
+	defer b.Close()
+
+	restore := b.GetSynchronous()
+	defer restore()
+	_ = a.Send("Hello\r\n", "")
+	p := gxcommon.NewReceiveParameters[string]()
+	p.EOP = "\r\n"
+	p.WaitTime = 1000
+	ok, err := b.Receive(p)
+	fmt.Printf("%v %v %q\n", ok, err, p.Reply)
+	fmt.Println(a.GetBytesSent(), b.GetBytesReceived())
+	// Output:
+	// true <nil> "Hello\r\n"
+	// 7 7
+}
+
+// ExampleNewLoopback shows the events raised by a loopback media.
+func ExampleNewLoopback() {
+	m := memory.NewLoopback()
+	m.SetOnMediaStateChange(func(_ gxcommon.IGXMedia, e gxcommon.MediaStateEventArgs) {
+		fmt.Println(e.State())
+	})
+	m.SetOnReceived(func(_ gxcommon.IGXMedia, e gxcommon.ReceiveEventArgs) {
+		fmt.Println(gxcommon.ToHex(e.Data()))
+	})
+	_ = m.Open()
+	_ = m.Send([]byte{1, 2, 3}, "")
+	_ = m.Close()
+	// Output:
+	// Opening
+	// Open
+	// 01 02 03
+	// Closing
+	// Closed
+}
@@ -0,0 +1,65 @@
+package memory_test
+
+import (
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/Gurux/gxcommon-go"
+	"github.com/Gurux/gxcommon-go/mediatest"
+	"github.com/Gurux/gxcommon-go/memory"
+)
+
+func TestConformance(t *testing.T) {
+	mediatest.RunConformance(t, func(*testing.T) (gxcommon.IGXMedia, gxcommon.IGXMedia) {
+		a, b := memory.NewPair()
+		return a, b
+	})
+}
+
+func TestLoopbackConformance(t *testing.T) {
+	mediatest.RunConformance(t, func(*testing.T) (gxcommon.IGXMedia, gxcommon.IGXMedia) {
+		m := memory.NewLoopback()
+		return m, m
+	})
+}
+
+func TestConcurrentOpenClose(t *testing.T) {
+	m := memory.NewLoopback()
+	var mu sync.Mutex
+	states := map[gxcommon.MediaState]int{}
+	m.SetOnMediaStateChange(func(_ gxcommon.IGXMedia, e gxcommon.MediaStateEventArgs) {
+		mu.Lock()
+		states[e.State()]++
+		mu.Unlock()
+		// Give the other calls time to see the state change.
+		time.Sleep(time.Millisecond)
+	})
+	run := func(fn func() error) {
+		var wg sync.WaitGroup
+		for range 20 {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				if err := fn(); err != nil {
+					t.Error(err)
+				}
+			}()
+		}
+		wg.Wait()
+	}
+	run(m.Open)
+	if !m.IsOpen() {
+		t.Fatal("media is not open")
+	}
+	run(m.Close)
+	if m.IsOpen() {
+		t.Fatal("media is open")
+	}
+	for _, it := range []gxcommon.MediaState{gxcommon.MediaStateOpening, gxcommon.MediaStateOpen,
+		gxcommon.MediaStateClosing, gxcommon.MediaStateClosed} {
+		if states[it] != 1 {
+			t.Errorf("%v events: %d, want 1", it, states[it])
+		}
+	}
+}
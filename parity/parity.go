@@ -0,0 +1,186 @@
+package parity
+
+// --------------------------------------------------------------------------
+//
+//	Gurux Ltd
+//
+// Filename:        $HeadURL$
+//
+// Version:         $Revision$,
+//
+//	$Date$
+//	$Author$
+//
+// # Copyright (c) Gurux Ltd
+//
+// ---------------------------------------------------------------------------
+//
+//	DESCRIPTION
+//
+// This file is a part of Gurux Device Framework.
+//
+// Gurux Device Framework is Open Source software; you can redistribute it
+// and/or modify it under the terms of the GNU General Public License
+// as published by the Free Software Foundation; version 2 of the License.
+// Gurux Device Framework is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.
+// See the GNU General Public License for more details.
+//
+// More information of Gurux products: https://www.gurux.org
+//
+// This code is licensed under the GNU General Public License v2.
+// Full text may be retrieved at http://www.gnu.org/licenses/gpl-2.0.txt
+// ---------------------------------------------------------------------------
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"math/bits"
+	"sync"
+
+	"github.com/Gurux/gxcommon-go"
+)
+
+// ErrParity means that a received character has an invalid parity bit.
+var ErrParity = errors.New("parity error")
+
+// bit returns the parity bit of the 7-bit character b.
+func bit(b byte, p gxcommon.Parity) byte {
+	var ret byte
+	switch p {
+	case gxcommon.ParityEven:
+		ret = byte(bits.OnesCount8(b) & 1)
+	case gxcommon.ParityOdd:
+		ret = byte(bits.OnesCount8(b)&1) ^ 1
+	case gxcommon.ParityMark:
+		ret = 1
+	}
+	return ret
+}
+
+// Encode returns data as 7-bit characters with the parity bit p in the
+// most significant bit.
+//
+// Data is returned unchanged with ParityNone. It returns ErrInvalidArgument
+// if a byte of data does not fit to 7 bits and ErrUnknownEnum if p is not a
+// defined Parity.
+func Encode(data []byte, p gxcommon.Parity) ([]byte, error) {
+	if p.String() == "" {
+		return nil, gxcommon.ErrUnknownEnumError("Parity")
+	}
+	if p == gxcommon.ParityNone {
+		return bytes.Clone(data), nil
+	}
+	ret := make([]byte, len(data))
+	for i, it := range data {
+		if it > 0x7F {
+			return nil, gxcommon.ErrInvalidArgumentError(fmt.Sprintf("data[%d]", i))
+		}
+		ret[i] = it | bit(it, p)<<7
+	}
+	return ret, nil
+}
+
+// Decode checks the parity bit p of the characters in data and returns them
+// without the parity bit.
+//
+// All characters are returned also when the parity bit of some of them is
+// invalid. The error then wraps ErrParity and tells the position of the
+// first invalid character. Data is returned unchanged with ParityNone. It
+// returns ErrUnknownEnum if p is not a defined Parity.
+func Decode(data []byte, p gxcommon.Parity) ([]byte, error) {
+	if p.String() == "" {
+		return nil, gxcommon.ErrUnknownEnumError("Parity")
+	}
+	if p == gxcommon.ParityNone {
+		return bytes.Clone(data), nil
+	}
+	ret := make([]byte, len(data))
+	var err error
+	for i, it := range data {
+		ret[i] = it & 0x7F
+		if err == nil && it>>7 != bit(ret[i], p) {
+			err = fmt.Errorf("%w in byte %d of %d", ErrParity, i, len(data))
+		}
+	}
+	return ret, err
+}
+
+// Media is an IGXMedia that sends 7-bit characters with a software parity
+// bit over an inner 8-bit media.
+//
+// Sent data is encoded with Encode. Received data is decoded with Decode and
+// delivered without the parity bit. Parity errors are reported through the
+// error event and error trace, and the data is still delivered so that the
+// protocol layer can reject it. Trace events and byte counters contain the
+// 7-bit data.
+type Media struct {
+	gxcommon.GXMediaWrapper
+	mu     sync.Mutex
+	parity gxcommon.Parity
+}
+
+// New returns a Media that uses parity p over inner.
+//
+// The received, error and media state handlers of inner are replaced. Use
+// the handlers of the returned Media instead.
+func New(inner gxcommon.IGXMedia, p gxcommon.Parity) *Media {
+	ret := &Media{parity: p}
+	ret.InitWrapper(ret, inner)
+	return ret
+}
+
+// Parity returns the emulated parity.
+func (m *Media) Parity() gxcommon.Parity {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.parity
+}
+
+// SetParity sets the emulated parity.
+func (m *Media) SetParity(value gxcommon.Parity) {
+	m.mu.Lock()
+	m.parity = value
+	m.mu.Unlock()
+}
+
+// Send adds the parity bit to data and sends it with the inner media.
+func (m *Media) Send(data any, receiver string) error {
+	b, err := gxcommon.ToBytes(data, m.GetByteOrder())
+	if err != nil {
+		return err
+	}
+	encoded, err := Encode(b, m.Parity())
+	if err != nil {
+		return err
+	}
+	if err = m.Inner().Send(encoded, receiver); err != nil {
+		return err
+	}
+	m.NotifySent(m, b, receiver)
+	return nil
+}
+
+// NotifyReceived checks and removes the parity bit of the data received from
+// the inner media.
+func (m *Media) NotifyReceived(media gxcommon.IGXMedia, data []byte, sender string) {
+	decoded, err := Decode(data, m.Parity())
+	if decoded == nil {
+		m.NotifyError(media, err)
+		return
+	}
+	if err != nil {
+		m.NotifyError(media, err)
+	}
+	m.GXMediaWrapper.NotifyReceived(media, decoded, sender)
+}
+
+// Validate validates the parity and the settings of the inner media.
+func (m *Media) Validate() error {
+	if m.Parity().String() == "" {
+		return gxcommon.ErrUnknownEnumError("Parity")
+	}
+	return m.GXMediaWrapper.Validate()
+}
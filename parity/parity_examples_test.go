@@ -0,0 +1,22 @@
+// Package parity_test holds examples for the parity package.
+package parity_test
+
+import (
+	"fmt"
+
+	"github.com/Gurux/gxcommon-go"
+	"github.com/Gurux/gxcommon-go/parity"
+)
+
+// ExampleEncode adds even parity to an IEC 62056-21 sign-on message and
+// detects a corrupted character.
+func ExampleEncode() {
+	data, _ := parity.Encode([]byte("/?!"), gxcommon.ParityEven)
+	fmt.Println(gxcommon.ToHex(data))
+	data[1] ^= 0x01
+	decoded, err := parity.Decode(data, gxcommon.ParityEven)
+	fmt.Printf("%q %v\n", decoded, err)
+	// Output:
+	// AF 3F 21
+	// "/>!" parity error in byte 1 of 3
+}
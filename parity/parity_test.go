@@ -0,0 +1,89 @@
+package parity_test
+
+import (
+	"errors"
+	"slices"
+	"testing"
+
+	"github.com/Gurux/gxcommon-go"
+	"github.com/Gurux/gxcommon-go/mediatest"
+	"github.com/Gurux/gxcommon-go/memory"
+	"github.com/Gurux/gxcommon-go/parity"
+)
+
+func TestConformance(t *testing.T) {
+	for _, it := range []gxcommon.Parity{gxcommon.ParityNone, gxcommon.ParityEven} {
+		t.Run(it.String(), func(t *testing.T) {
+			mediatest.RunConformance(t, func(*testing.T) (gxcommon.IGXMedia, gxcommon.IGXMedia) {
+				a, b := memory.NewPair()
+				return parity.New(a, it), parity.New(b, it)
+			})
+		})
+	}
+}
+
+func TestEncodeDecode(t *testing.T) {
+	tests := []struct {
+		parity gxcommon.Parity
+		want   []byte
+	}{
+		{gxcommon.ParityEven, []byte{0x00, 0x81, 0x03, 0xFF}},
+		{gxcommon.ParityOdd, []byte{0x80, 0x01, 0x83, 0x7F}},
+		{gxcommon.ParityMark, []byte{0x80, 0x81, 0x83, 0xFF}},
+		{gxcommon.ParitySpace, []byte{0x00, 0x01, 0x03, 0x7F}},
+	}
+	data := []byte{0x00, 0x01, 0x03, 0x7F}
+	for _, tt := range tests {
+		t.Run(tt.parity.String(), func(t *testing.T) {
+			encoded, err := parity.Encode(data, tt.parity)
+			if err != nil || string(encoded) != string(tt.want) {
+				t.Fatalf("Encode = % X, %v, want % X", encoded, err, tt.want)
+			}
+			decoded, err := parity.Decode(encoded, tt.parity)
+			if err != nil || string(decoded) != string(data) {
+				t.Fatalf("Decode = % X, %v", decoded, err)
+			}
+			encoded[2] ^= 0x80
+			if _, err = parity.Decode(encoded, tt.parity); !errors.Is(err, parity.ErrParity) {
+				t.Fatalf("Decode error = %v, want ErrParity", err)
+			}
+		})
+	}
+	if _, err := parity.Encode([]byte{0x80}, gxcommon.ParityEven); !errors.Is(err, gxcommon.ErrInvalidArgument) {
+		t.Errorf("Encode of 8-bit data = %v, want ErrInvalidArgument", err)
+	}
+}
+
+func TestParityError(t *testing.T) {
+	a, b := memory.NewPair()
+	m := parity.New(b, gxcommon.ParityEven)
+	c := mediatest.NewCollector(m)
+	var traces []gxcommon.TraceEventArgs
+	_ = m.SetTrace(gxcommon.TraceLevelError)
+	m.SetOnTrace(func(_ gxcommon.IGXMedia, e gxcommon.TraceEventArgs) {
+		traces = append(traces, e)
+	})
+	if err := a.Open(); err != nil {
+		t.Fatal(err)
+	}
+	if err := m.Open(); err != nil {
+		t.Fatal(err)
+	}
+	// '1' (0x31) has three bits set, so even parity sets the parity bit.
+	if err := a.Send([]byte{0xB1, 0x31}, ""); err != nil {
+		t.Fatal(err)
+	}
+	if received := c.ReceivedStrings(); !slices.Equal(received, []string{"11"}) {
+		t.Errorf("received %q, want [\"11\"]", received)
+	}
+	errs := c.Errors()
+	if len(errs) != 1 || !errors.Is(errs[0], parity.ErrParity) {
+		t.Errorf("errors %v, want one ErrParity", errs)
+	}
+	if len(traces) != 1 || traces[0].Type() != gxcommon.TraceTypesError {
+		t.Fatalf("traces %v, want one error trace", traces)
+	}
+	if err, ok := traces[0].Data().(error); !ok || !errors.Is(err, parity.ErrParity) {
+		t.Errorf("trace data %v, want ErrParity", traces[0].Data())
+	}
+}
@@ -0,0 +1,49 @@
+// Package pcapng_test holds examples for the pcapng package.
+package pcapng_test
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+
+	"github.com/Gurux/gxcommon-go"
+	"github.com/Gurux/gxcommon-go/memory"
+	"github.com/Gurux/gxcommon-go/pcapng"
+)
+
+// ExampleWriter captures the traffic of a media pair and reads it back.
+func ExampleWriter() {
+	var file bytes.Buffer
+	w, err := pcapng.NewWriter(&file)
+	if err != nil {
+		fmt.Println(err)
+		return
+	}
+	a, b := memory.NewPair()
+	for _, it := range []*memory.Media{a, b} {
+		it.SetOnTrace(w.TraceHandler())
+		_ = it.SetTrace(gxcommon.TraceLevelVerbose)
+		_ = it.Open()
+	}
+	_ = a.Send([]byte{0x7E, 0xA0, 0x7E}, "")
+
+	r, err := pcapng.NewReader(&file)
+	if err != nil {
+		fmt.Println(err)
+		return
+	}
+	for {
+		name, e, err := r.Next()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			fmt.Println(err)
+			return
+		}
+		fmt.Println(name, e.Type(), e.Receiver, gxcommon.ToHex(e.Data().([]byte)))
+	}
+	// Output:
+	// A Sent  7E A0 7E
+	// B Received A 7E A0 7E
+}
@@ -0,0 +1,272 @@
+package pcapng_test
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"io"
+	"testing"
+	"time"
+
+	"github.com/Gurux/gxcommon-go"
+	"github.com/Gurux/gxcommon-go/pcapng"
+)
+
+// block returns a block with the given type and body.
+func block(order binary.AppendByteOrder, blockType uint32, body []byte) []byte {
+	size := uint32(len(body) + 12)
+	ret := order.AppendUint32(nil, blockType)
+	ret = order.AppendUint32(ret, size)
+	ret = append(ret, body...)
+	return order.AppendUint32(ret, size)
+}
+
+// shb returns a section header block.
+func shb(order binary.AppendByteOrder) []byte {
+	body := order.AppendUint32(nil, 0x1A2B3C4D)
+	body = order.AppendUint16(body, 1)
+	body = order.AppendUint16(body, 0)
+	body = order.AppendUint64(body, 0xFFFFFFFFFFFFFFFF)
+	return block(order, 0x0A0D0D0A, body)
+}
+
+// option returns an option with a padded value.
+func option(order binary.AppendByteOrder, code uint16, value []byte) []byte {
+	ret := order.AppendUint16(nil, code)
+	ret = order.AppendUint16(ret, uint16(len(value)))
+	ret = append(ret, value...)
+	return append(ret, make([]byte, (4-len(value)%4)%4)...)
+}
+
+// idb returns an interface description block with a name and a timestamp
+// resolution.
+func idb(order binary.AppendByteOrder, name string, tsresol byte) []byte {
+	body := order.AppendUint16(nil, 147)
+	body = order.AppendUint16(body, 0)
+	body = order.AppendUint32(body, 0)
+	body = append(body, option(order, 2, []byte(name))...)
+	body = append(body, option(order, 9, []byte{tsresol})...)
+	body = append(body, option(order, 0, nil)...)
+	return block(order, 1, body)
+}
+
+// epb returns an enhanced packet block.
+func epb(order binary.AppendByteOrder, id uint32, ts uint64, data []byte, flags uint32) []byte {
+	body := order.AppendUint32(nil, id)
+	body = order.AppendUint32(body, uint32(ts>>32))
+	body = order.AppendUint32(body, uint32(ts))
+	body = order.AppendUint32(body, uint32(len(data)))
+	body = order.AppendUint32(body, uint32(len(data)))
+	body = append(body, data...)
+	body = append(body, make([]byte, (4-len(data)%4)%4)...)
+	body = append(body, option(order, 2, order.AppendUint32(nil, flags))...)
+	return block(order, 6, body)
+}
+
+// readAll reads all events of a capture.
+func readAll(data []byte) ([]string, []*gxcommon.TraceEventArgs, error) {
+	r, err := pcapng.NewReader(bytes.NewReader(data))
+	if err != nil {
+		return nil, nil, err
+	}
+	var names []string
+	var events []*gxcommon.TraceEventArgs
+	for {
+		name, e, err := r.Next()
+		if err == io.EOF {
+			return names, events, nil
+		}
+		if err != nil {
+			return names, events, err
+		}
+		names = append(names, name)
+		events = append(events, e)
+	}
+}
+
+func TestRoundTrip(t *testing.T) {
+	var buf bytes.Buffer
+	w, err := pcapng.NewWriter(&buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	ts := time.Date(2024, 5, 6, 7, 8, 9, 123456789, time.UTC)
+	events := []struct {
+		name string
+		e    *gxcommon.TraceEventArgs
+	}{
+		{"A", gxcommon.NewTraceEventArgsAt(ts, gxcommon.TraceTypesSent, []byte{1, 2, 3}, "B")},
+		{"B", gxcommon.NewTraceEventArgsAt(ts.Add(time.Nanosecond), gxcommon.TraceTypesReceived, []byte{1, 2, 3}, "A")},
+		{"A", gxcommon.NewTraceEventArgsAt(ts.Add(time.Hour), gxcommon.TraceTypesReceived, []byte{}, "")},
+	}
+	for _, it := range events {
+		if err = w.WriteEvent(it.name, it.e); err != nil {
+			t.Fatal(err)
+		}
+	}
+	names, got, err := readAll(buf.Bytes())
+	if err != nil || len(got) != len(events) {
+		t.Fatalf("got %d events, %v", len(got), err)
+	}
+	for i, it := range events {
+		e := got[i]
+		if names[i] != it.name || !e.Timestamp().Equal(it.e.Timestamp()) || e.Type() != it.e.Type() ||
+			e.Receiver != it.e.Receiver || !bytes.Equal(e.Data().([]byte), it.e.Data().([]byte)) {
+			t.Errorf("event %d: got %s %v %s %q %X", i, names[i], e.Timestamp(), e.Type(), e.Receiver, e.Data())
+		}
+	}
+}
+
+func TestWriteTimestampRange(t *testing.T) {
+	var buf bytes.Buffer
+	w, err := pcapng.NewWriter(&buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, it := range []time.Time{
+		{},
+		time.Date(1969, 12, 31, 23, 59, 59, 999999999, time.UTC),
+		time.Date(2263, 1, 1, 0, 0, 0, 0, time.UTC),
+	} {
+		e := gxcommon.NewTraceEventArgsAt(it, gxcommon.TraceTypesSent, []byte{1}, "")
+		if err = w.WriteEvent("A", e); !errors.Is(err, gxcommon.ErrInvalidArgument) {
+			t.Errorf("WriteEvent at %v: %v", it, err)
+		}
+	}
+	if w.Err() != nil {
+		t.Fatalf("invalid timestamps broke the writer: %v", w.Err())
+	}
+	epoch := time.Unix(0, 0)
+	if err = w.WriteEvent("A", gxcommon.NewTraceEventArgsAt(epoch, gxcommon.TraceTypesSent, []byte{1}, "")); err != nil {
+		t.Fatal(err)
+	}
+	_, events, err := readAll(buf.Bytes())
+	if err != nil || len(events) != 1 || !events[0].Timestamp().Equal(epoch) {
+		t.Errorf("got %v, %v", events, err)
+	}
+}
+
+func TestTimestampResolution(t *testing.T) {
+	tests := []struct {
+		tsresol byte
+		ts      uint64
+		want    time.Time
+	}{
+		{3, 1500, time.Unix(1, 500000000)},
+		{6, 2000001, time.Unix(2, 1000)},
+		{9, 3000000001, time.Unix(3, 1)},
+		{0x80 | 10, 1024 + 512, time.Unix(1, 500000000)},
+		{0, 42, time.Unix(42, 0)},
+	}
+	for _, tt := range tests {
+		order := binary.LittleEndian
+		data := shb(order)
+		data = append(data, idb(order, "A", tt.tsresol)...)
+		data = append(data, epb(order, 0, tt.ts, []byte{1}, 2)...)
+		_, events, err := readAll(data)
+		if err != nil || len(events) != 1 || !events[0].Timestamp().Equal(tt.want) {
+			t.Errorf("if_tsresol %02X: got %v, %v", tt.tsresol, events, err)
+		}
+	}
+}
+
+func TestBigEndian(t *testing.T) {
+	order := binary.BigEndian
+	data := shb(order)
+	data = append(data, idb(order, "Serial", 6)...)
+	data = append(data, epb(order, 0, 1000001, []byte{0x7E, 0xA0}, 2)...)
+	data = append(data, epb(order, 0, 2000000, []byte{0x7E}, 1)...)
+	names, events, err := readAll(data)
+	if err != nil || len(events) != 2 {
+		t.Fatalf("got %d events, %v", len(events), err)
+	}
+	if names[0] != "Serial" || events[0].Type() != gxcommon.TraceTypesSent ||
+		!events[0].Timestamp().Equal(time.Unix(1, 1000)) || gxcommon.ToHex(events[0].Data().([]byte)) != "7E A0" {
+		t.Errorf("got %s %s %v %X", names[0], events[0].Type(), events[0].Timestamp(), events[0].Data())
+	}
+	if events[1].Type() != gxcommon.TraceTypesReceived || !events[1].Timestamp().Equal(time.Unix(2, 0)) {
+		t.Errorf("got %s %v", events[1].Type(), events[1].Timestamp())
+	}
+	// A new section can change the byte order.
+	data = append(data, shb(binary.LittleEndian)...)
+	data = append(data, idb(binary.LittleEndian, "TCP", 9)...)
+	data = append(data, epb(binary.LittleEndian, 0, 5, []byte{1}, 1)...)
+	names, events, err = readAll(data)
+	if err != nil || len(events) != 3 || names[2] != "TCP" || !events[2].Timestamp().Equal(time.Unix(0, 5)) {
+		t.Errorf("got %d events %q, %v", len(events), names, err)
+	}
+}
+
+func TestMalformed(t *testing.T) {
+	order := binary.LittleEndian
+	header := append(shb(order), idb(order, "A", 9)...)
+	packet := epb(order, 0, 1, []byte{1, 2, 3}, 2)
+	withHeader := func(data ...[]byte) []byte {
+		return append(bytes.Clone(header), bytes.Join(data, nil)...)
+	}
+	odd := bytes.Clone(packet)
+	order.PutUint32(odd[4:], uint32(len(odd)+1))
+	short := bytes.Clone(packet)
+	order.PutUint32(short[4:], 8)
+	mismatch := bytes.Clone(packet)
+	order.PutUint32(mismatch[len(mismatch)-4:], uint32(len(mismatch)+4))
+	huge := bytes.Clone(packet)
+	order.PutUint32(huge[4:], 0xFFFFFFF0)
+	captured := bytes.Clone(packet)
+	order.PutUint32(captured[20:], 1000)
+	badMagic := shb(order)
+	order.PutUint32(badMagic[8:], 0x12345678)
+	tests := []struct {
+		name string
+		data []byte
+		err  error
+	}{
+		{"empty", nil, io.ErrUnexpectedEOF},
+		{"no section header", packet, gxcommon.ErrInvalidArgument},
+		{"bad byte-order magic", badMagic, gxcommon.ErrInvalidArgument},
+		{"odd block length", withHeader(odd), gxcommon.ErrInvalidArgument},
+		{"block length too small", withHeader(short), gxcommon.ErrInvalidArgument},
+		{"block length too large", withHeader(huge), gxcommon.ErrInvalidArgument},
+		{"trailing length mismatch", withHeader(mismatch), gxcommon.ErrInvalidArgument},
+		{"truncated packet", withHeader(packet[:len(packet)-6]), io.ErrUnexpectedEOF},
+		{"truncated header", withHeader(packet[:6]), io.ErrUnexpectedEOF},
+		{"short packet block", withHeader(block(order, 6, make([]byte, 16))), gxcommon.ErrInvalidArgument},
+		{"captured length", withHeader(captured), gxcommon.ErrInvalidArgument},
+		{"unknown interface", withHeader(epb(order, 1, 1, []byte{1}, 2)), gxcommon.ErrInvalidArgument},
+		{"interface of previous section", append(withHeader(shb(order)), packet...), gxcommon.ErrInvalidArgument},
+		{"option length", withHeader(block(order, 1, append(make([]byte, 8), option(order, 2, []byte("A"))[:4]...))), gxcommon.ErrInvalidArgument},
+	}
+	for _, tt := range tests {
+		_, events, err := readAll(tt.data)
+		if !errors.Is(err, tt.err) {
+			t.Errorf("%s: got %d events, %v, want %v", tt.name, len(events), err, tt.err)
+		}
+	}
+}
+
+func FuzzReader(f *testing.F) {
+	var buf bytes.Buffer
+	w, err := pcapng.NewWriter(&buf)
+	if err != nil {
+		f.Fatal(err)
+	}
+	_ = w.WriteEvent("A", gxcommon.NewTraceEventArgsAt(time.Unix(1, 2), gxcommon.TraceTypesSent, []byte{1, 2, 3}, "B"))
+	f.Add(buf.Bytes())
+	order := binary.BigEndian
+	f.Add(append(append(shb(order), idb(order, "A", 0x8A)...), epb(order, 0, 1, []byte{1}, 1)...))
+	f.Fuzz(func(t *testing.T, data []byte) {
+		r, err := pcapng.NewReader(bytes.NewReader(data))
+		if err != nil {
+			return
+		}
+		for {
+			_, e, err := r.Next()
+			if err != nil {
+				return
+			}
+			if _, ok := e.Data().([]byte); !ok {
+				t.Fatalf("data is %T", e.Data())
+			}
+		}
+	})
+}
@@ -0,0 +1,255 @@
+package pcapng
+
+// --------------------------------------------------------------------------
+//
+//	Gurux Ltd
+//
+// Filename:        $HeadURL$
+//
+// Version:         $Revision$,
+//
+//	$Date$
+//	$Author$
+//
+// # Copyright (c) Gurux Ltd
+//
+// ---------------------------------------------------------------------------
+//
+//	DESCRIPTION
+//
+// This file is a part of Gurux Device Framework.
+//
+// Gurux Device Framework is Open Source software; you can redistribute it
+// and/or modify it under the terms of the GNU General Public License
+// as published by the Free Software Foundation; version 2 of the License.
+// Gurux Device Framework is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.
+// See the GNU General Public License for more details.
+//
+// More information of Gurux products: https://www.gurux.org
+//
+// This code is licensed under the GNU General Public License v2.
+// Full text may be retrieved at http://www.gnu.org/licenses/gpl-2.0.txt
+// ---------------------------------------------------------------------------
+
+import (
+	"encoding/binary"
+	"io"
+	"math"
+	"time"
+
+	"github.com/Gurux/gxcommon-go"
+)
+
+// maxBlockSize is the largest block that Reader accepts.
+const maxBlockSize = 16 * 1024 * 1024
+
+// iface is an interface of the current section.
+type iface struct {
+	name string
+	// units is the number of timestamp units in a second.
+	units uint64
+}
+
+// Reader reads trace events from a pcapng stream.
+//
+// Enhanced packet blocks are returned as TraceEventArgs. Packets with the
+// outbound direction flag are TraceTypesSent events and other packets are
+// TraceTypesReceived events. Other block types are skipped.
+type Reader struct {
+	r          io.Reader
+	order      binary.ByteOrder
+	interfaces []iface
+}
+
+// NewReader reads the section header from r and returns a Reader.
+//
+// It returns ErrInvalidArgument if r does not start with a pcapng section
+// header.
+func NewReader(r io.Reader) (*Reader, error) {
+	ret := &Reader{r: r}
+	blockType, _, err := ret.readBlock()
+	if err != nil {
+		return nil, unexpected(err)
+	}
+	if blockType != blockTypeSHB {
+		return nil, gxcommon.ErrInvalidArgumentError("section header")
+	}
+	return ret, nil
+}
+
+// Next returns the next trace event and the name of the interface it was
+// captured on. It returns io.EOF when there are no more events.
+func (r *Reader) Next() (string, *gxcommon.TraceEventArgs, error) {
+	for {
+		blockType, body, err := r.readBlock()
+		if err != nil {
+			return "", nil, err
+		}
+		switch blockType {
+		case blockTypeIDB:
+			if err = r.readInterface(body); err != nil {
+				return "", nil, err
+			}
+		case blockTypeEPB:
+			return r.readPacket(body)
+		}
+	}
+}
+
+// readInterface reads an interface description block.
+func (r *Reader) readInterface(body []byte) error {
+	if len(body) < 8 {
+		return gxcommon.ErrInvalidArgumentError("interface description block")
+	}
+	it := iface{units: 1000000}
+	err := r.readOptions(body[8:], func(code uint16, value []byte) {
+		switch code {
+		case optIfName:
+			it.name = string(value)
+		case optIfTsresol:
+			if len(value) == 1 {
+				exp := value[0] & 0x7F
+				base := uint64(10)
+				if value[0]&0x80 != 0 {
+					base = 2
+				} else if exp > 19 {
+					return
+				}
+				if exp > 63 {
+					return
+				}
+				it.units = 1
+				for range exp {
+					it.units *= base
+				}
+			}
+		}
+	})
+	if err != nil {
+		return err
+	}
+	r.interfaces = append(r.interfaces, it)
+	return nil
+}
+
+// readPacket reads an enhanced packet block.
+func (r *Reader) readPacket(body []byte) (string, *gxcommon.TraceEventArgs, error) {
+	if len(body) < 20 {
+		return "", nil, gxcommon.ErrInvalidArgumentError("enhanced packet block")
+	}
+	id := r.order.Uint32(body)
+	if int(id) >= len(r.interfaces) {
+		return "", nil, gxcommon.ErrInvalidArgumentError("interface ID")
+	}
+	it := r.interfaces[id]
+	ts := uint64(r.order.Uint32(body[4:]))<<32 | uint64(r.order.Uint32(body[8:]))
+	size := int(r.order.Uint32(body[12:]))
+	padded := (size + 3) &^ 3
+	if size < 0 || 20+padded > len(body) {
+		return "", nil, gxcommon.ErrInvalidArgumentError("captured packet length")
+	}
+	data := make([]byte, size)
+	copy(data, body[20:])
+	traceType := gxcommon.TraceTypesReceived
+	receiver := ""
+	err := r.readOptions(body[20+padded:], func(code uint16, value []byte) {
+		switch code {
+		case optEpbFlags:
+			if len(value) == 4 && r.order.Uint32(value)&3 == flagOutbound {
+				traceType = gxcommon.TraceTypesSent
+			}
+		case optComment:
+			receiver = string(value)
+		}
+	})
+	if err != nil {
+		return "", nil, err
+	}
+	return it.name, gxcommon.NewTraceEventArgsAt(timestamp(ts, it.units), traceType, data, receiver), nil
+}
+
+// readOptions calls fn for each option in data.
+func (r *Reader) readOptions(data []byte, fn func(code uint16, value []byte)) error {
+	for len(data) >= 4 {
+		code := r.order.Uint16(data)
+		size := int(r.order.Uint16(data[2:]))
+		if code == optEndOfOpt {
+			break
+		}
+		padded := (size + 3) &^ 3
+		if 4+padded > len(data) {
+			return gxcommon.ErrInvalidArgumentError("option length")
+		}
+		fn(code, data[4:4+size])
+		data = data[4+padded:]
+	}
+	return nil
+}
+
+// readBlock reads the next block and returns its type and body.
+func (r *Reader) readBlock() (uint32, []byte, error) {
+	var header [12]byte
+	if _, err := io.ReadFull(r.r, header[:8]); err != nil {
+		return 0, nil, err
+	}
+	if binary.LittleEndian.Uint32(header[:]) == blockTypeSHB {
+		// A new section can change the byte order.
+		if _, err := io.ReadFull(r.r, header[8:]); err != nil {
+			return 0, nil, unexpected(err)
+		}
+		switch {
+		case binary.LittleEndian.Uint32(header[8:]) == byteOrderMagic:
+			r.order = binary.LittleEndian
+		case binary.BigEndian.Uint32(header[8:]) == byteOrderMagic:
+			r.order = binary.BigEndian
+		default:
+			return 0, nil, gxcommon.ErrInvalidArgumentError("byte-order magic")
+		}
+		r.interfaces = nil
+	} else if r.order == nil {
+		return 0, nil, gxcommon.ErrInvalidArgumentError("section header")
+	}
+	blockType := r.order.Uint32(header[:])
+	size := r.order.Uint32(header[4:])
+	if size < 12 || size%4 != 0 || size > maxBlockSize || (blockType == blockTypeSHB && size < 28) {
+		return 0, nil, gxcommon.ErrInvalidArgumentError("block length")
+	}
+	buf := make([]byte, size-8)
+	read := 0
+	if blockType == blockTypeSHB {
+		read = copy(buf, header[8:])
+	}
+	if _, err := io.ReadFull(r.r, buf[read:]); err != nil {
+		return 0, nil, unexpected(err)
+	}
+	if r.order.Uint32(buf[len(buf)-4:]) != size {
+		return 0, nil, gxcommon.ErrInvalidArgumentError("block length")
+	}
+	return blockType, buf[:len(buf)-4], nil
+}
+
+// unexpected converts io.EOF to io.ErrUnexpectedEOF.
+func unexpected(err error) error {
+	if err == io.EOF {
+		return io.ErrUnexpectedEOF
+	}
+	return err
+}
+
+// timestamp converts a timestamp in units per second to time.
+func timestamp(ts uint64, units uint64) time.Time {
+	sec := ts / units
+	frac := ts % units
+	if sec > math.MaxInt64 {
+		sec = math.MaxInt64
+	}
+	var nsec uint64
+	if units > 1000000000 {
+		nsec = frac / (units / 1000000000)
+	} else {
+		nsec = frac * 1000000000 / units
+	}
+	return time.Unix(int64(sec), int64(nsec))
+}
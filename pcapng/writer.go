@@ -0,0 +1,222 @@
+package pcapng
+
+// --------------------------------------------------------------------------
+//
+//	Gurux Ltd
+//
+// Filename:        $HeadURL$
+//
+// Version:         $Revision$,
+//
+//	$Date$
+//	$Author$
+//
+// # Copyright (c) Gurux Ltd
+//
+// ---------------------------------------------------------------------------
+//
+//	DESCRIPTION
+//
+// This file is a part of Gurux Device Framework.
+//
+// Gurux Device Framework is Open Source software; you can redistribute it
+// and/or modify it under the terms of the GNU General Public License
+// as published by the Free Software Foundation; version 2 of the License.
+// Gurux Device Framework is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.
+// See the GNU General Public License for more details.
+//
+// More information of Gurux products: https://www.gurux.org
+//
+// This code is licensed under the GNU General Public License v2.
+// Full text may be retrieved at http://www.gnu.org/licenses/gpl-2.0.txt
+// ---------------------------------------------------------------------------
+
+import (
+	"encoding/binary"
+	"io"
+	"math"
+	"sync"
+	"time"
+
+	"github.com/Gurux/gxcommon-go"
+)
+
+// LinkTypeUser0 is the first user-defined link type (DLT_USER0).
+const LinkTypeUser0 = 147
+
+const (
+	// blockTypeSHB is the section header block type.
+	blockTypeSHB = 0x0A0D0D0A
+	// blockTypeIDB is the interface description block type.
+	blockTypeIDB = 1
+	// blockTypeEPB is the enhanced packet block type.
+	blockTypeEPB = 6
+	// byteOrderMagic tells the byte order of a section.
+	byteOrderMagic = 0x1A2B3C4D
+
+	// optEndOfOpt ends the option list.
+	optEndOfOpt = 0
+	// optComment is a comment option.
+	optComment = 1
+	// optIfName is the interface name option of IDB.
+	optIfName = 2
+	// optIfTsresol is the timestamp resolution option of IDB.
+	optIfTsresol = 9
+	// optEpbFlags is the flags option of EPB.
+	optEpbFlags = 2
+
+	// flagInbound is the inbound direction of the EPB flags.
+	flagInbound = 1
+	// flagOutbound is the outbound direction of the EPB flags.
+	flagOutbound = 2
+)
+
+// Writer writes sent and received trace events to a pcapng stream.
+//
+// Each media name gets its own interface. Timestamps are written with
+// nanosecond resolution, the direction is stored in the EPB flags and the
+// receiver of the event as a packet comment. Writer is safe for concurrent
+// use.
+type Writer struct {
+	mu         sync.Mutex
+	w          io.Writer
+	linkType   uint16
+	interfaces map[string]uint32
+	err        error
+}
+
+// NewWriter writes the section header to w and returns a Writer that uses
+// LinkTypeUser0.
+func NewWriter(w io.Writer) (*Writer, error) {
+	return NewWriterLinkType(w, LinkTypeUser0)
+}
+
+// NewWriterLinkType is like NewWriter, but the interfaces use linkType.
+func NewWriterLinkType(w io.Writer, linkType uint16) (*Writer, error) {
+	ret := &Writer{w: w, linkType: linkType, interfaces: map[string]uint32{}}
+	body := binary.LittleEndian.AppendUint32(nil, byteOrderMagic)
+	body = binary.LittleEndian.AppendUint16(body, 1)
+	body = binary.LittleEndian.AppendUint16(body, 0)
+	// Section length is not specified.
+	body = binary.LittleEndian.AppendUint64(body, 0xFFFFFFFFFFFFFFFF)
+	if err := ret.writeBlock(blockTypeSHB, body); err != nil {
+		return nil, err
+	}
+	return ret, nil
+}
+
+// WriteEvent writes a trace event of the named media.
+//
+// Only TraceTypesSent and TraceTypesReceived events are written. Other
+// events are ignored. The event data is converted to bytes with
+// gxcommon.ToBytes using big-endian byte order.
+//
+// It returns ErrInvalidArgument if the timestamp of the event is before
+// 1970 or after 2262, because it is written as nanoseconds since 1970.
+func (w *Writer) WriteEvent(name string, e *gxcommon.TraceEventArgs) error {
+	var flags uint32
+	switch e.Type() {
+	case gxcommon.TraceTypesSent:
+		flags = flagOutbound
+	case gxcommon.TraceTypesReceived:
+		flags = flagInbound
+	default:
+		return nil
+	}
+	if t := e.Timestamp(); t.Before(time.Unix(0, 0)) || t.After(time.Unix(0, math.MaxInt64)) {
+		return gxcommon.ErrInvalidArgumentError("timestamp")
+	}
+	data, err := gxcommon.ToBytes(e.Data(), binary.BigEndian)
+	if err != nil {
+		return err
+	}
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if w.err != nil {
+		return w.err
+	}
+	id, ok := w.interfaces[name]
+	if !ok {
+		if id, err = w.writeInterface(name); err != nil {
+			return err
+		}
+	}
+	ts := uint64(e.Timestamp().UnixNano())
+	body := binary.LittleEndian.AppendUint32(nil, id)
+	body = binary.LittleEndian.AppendUint32(body, uint32(ts>>32))
+	body = binary.LittleEndian.AppendUint32(body, uint32(ts))
+	body = binary.LittleEndian.AppendUint32(body, uint32(len(data)))
+	body = binary.LittleEndian.AppendUint32(body, uint32(len(data)))
+	body = appendPadded(body, data)
+	body = appendOption(body, optEpbFlags, binary.LittleEndian.AppendUint32(nil, flags))
+	if e.Receiver != "" {
+		body = appendOption(body, optComment, []byte(e.Receiver))
+	}
+	body = appendOption(body, optEndOfOpt, nil)
+	return w.writeBlock(blockTypeEPB, body)
+}
+
+// TraceHandler returns a trace event handler that writes the events of a
+// media with WriteEvent using GetName as the interface name.
+//
+// Write errors are reported by Err.
+func (w *Writer) TraceHandler() gxcommon.TraceEventHandler {
+	return func(media gxcommon.IGXMedia, e gxcommon.TraceEventArgs) {
+		_ = w.WriteEvent(media.GetName(), &e)
+	}
+}
+
+// Err returns the first write error.
+func (w *Writer) Err() error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	return w.err
+}
+
+// writeInterface writes an interface description block for name. The caller
+// must hold w.mu.
+func (w *Writer) writeInterface(name string) (uint32, error) {
+	id := uint32(len(w.interfaces))
+	body := binary.LittleEndian.AppendUint16(nil, w.linkType)
+	body = binary.LittleEndian.AppendUint16(body, 0)
+	// Snap length is not limited.
+	body = binary.LittleEndian.AppendUint32(body, 0)
+	body = appendOption(body, optIfName, []byte(name))
+	// Timestamps are in nanoseconds.
+	body = appendOption(body, optIfTsresol, []byte{9})
+	body = appendOption(body, optEndOfOpt, nil)
+	if err := w.writeBlock(blockTypeIDB, body); err != nil {
+		return 0, err
+	}
+	w.interfaces[name] = id
+	return id, nil
+}
+
+// writeBlock writes a block with the given type and body.
+func (w *Writer) writeBlock(blockType uint32, body []byte) error {
+	size := uint32(len(body) + 12)
+	buf := binary.LittleEndian.AppendUint32(make([]byte, 0, size), blockType)
+	buf = binary.LittleEndian.AppendUint32(buf, size)
+	buf = append(buf, body...)
+	buf = binary.LittleEndian.AppendUint32(buf, size)
+	if _, err := w.w.Write(buf); err != nil {
+		w.err = err
+		return err
+	}
+	return nil
+}
+
+// appendOption appends an option with a padded value.
+func appendOption(body []byte, code uint16, value []byte) []byte {
+	body = binary.LittleEndian.AppendUint16(body, code)
+	body = binary.LittleEndian.AppendUint16(body, uint16(len(value)))
+	return appendPadded(body, value)
+}
+
+// appendPadded appends data padded to 32 bits.
+func appendPadded(body []byte, data []byte) []byte {
+	body = append(body, data...)
+	return append(body, make([]byte, (4-len(data)%4)%4)...)
+}
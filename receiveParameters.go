@@ -33,6 +33,8 @@ package gxcommon
 // Full text may be retrieved at http://www.gnu.org/licenses/gpl-2.0.txt
 // ---------------------------------------------------------------------------
 
+import "encoding/binary"
+
 // ReceiveParameters defines options for synchronous receive operations.
 type ReceiveParameters struct {
 	// Peek returns bytes from the buffer without consuming them.
@@ -42,24 +44,37 @@ type ReceiveParameters struct {
 	// It can be, for example, a single byte, a string, or a byte slice.
 	EOP any
 
-	// Count is the number of bytes to read.
+	// Count is the number of bytes to read, or the number of elements if
+	// the reply type is a slice type.
+	// If EOP is also set, Count is the minimum reply length.
 	Count int
 
+	// Framer detects the end of the reply. When set, EOP, Count and AllData
+	// are ignored and Reply is the next frame returned by the framer.
+	Framer Framer
+
 	// WaitTime is the maximum wait time in milliseconds.
-	// A value of -1 means infinite wait.
+	// A value of -1 means infinite wait and 0 means that only already
+	// received data is checked.
 	WaitTime int
 
 	// AllData moves all available reply data to Reply when true.
+	// Available data is moved also when WaitTime elapses.
 	AllData bool
 
 	// Reply contains received reply data.
 	Reply any
 
 	// ReplyType is the expected reply data type.
-	// Supported types are string, []byte, uint8, rune, int16, int32, int64,
-	// uint16, uint32, and uint64. If ReplyType is DataTypeUnknown, the type
-	// is inferred from Reply.
+	// All DataType values are supported. Use DataTypeRune to receive a
+	// UTF-8 encoded rune. If ReplyType is DataTypeUnknown, the type is
+	// inferred from Reply.
 	ReplyType DataType
+
+	// ByteOrder is the byte order used to convert EOP and the reply. If it
+	// is nil, the byte order of the receive buffer is used, which is
+	// big-endian unless the media sets another one.
+	ByteOrder binary.ByteOrder
 }
 
 // NewReceiveParameters returns a new ReceiveParameters initialized with
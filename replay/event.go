@@ -0,0 +1,107 @@
+package replay
+
+// --------------------------------------------------------------------------
+//
+//	Gurux Ltd
+//
+// Filename:        $HeadURL$
+//
+// Version:         $Revision$,
+//
+//	$Date$
+//	$Author$
+//
+// # Copyright (c) Gurux Ltd
+//
+// ---------------------------------------------------------------------------
+//
+//	DESCRIPTION
+//
+// This file is a part of Gurux Device Framework.
+//
+// Gurux Device Framework is Open Source software; you can redistribute it
+// and/or modify it under the terms of the GNU General Public License
+// as published by the Free Software Foundation; version 2 of the License.
+// Gurux Device Framework is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.
+// See the GNU General Public License for more details.
+//
+// More information of Gurux products: https://www.gurux.org
+//
+// This code is licensed under the GNU General Public License v2.
+// Full text may be retrieved at http://www.gnu.org/licenses/gpl-2.0.txt
+// ---------------------------------------------------------------------------
+
+import (
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"time"
+)
+
+// ErrMismatch means that sent data does not match the recording.
+var ErrMismatch = errors.New("sent data does not match the recording")
+
+// EventType is the type of a recorded event.
+type EventType string
+
+const (
+	// EventSend is data sent to the device.
+	EventSend EventType = "send"
+	// EventReceive is data received from the device.
+	EventReceive EventType = "receive"
+	// EventState is a media state change.
+	EventState EventType = "state"
+	// EventError is a media error.
+	EventError EventType = "error"
+	// EventSendError is an error returned by the send before it.
+	EventSendError EventType = "send error"
+)
+
+// Event is a recorded media event.
+//
+// Events are stored as JSON lines where data is written as hex.
+type Event struct {
+	// Time is the time since the recording started.
+	Time time.Duration
+	// Type is the event type.
+	Type EventType
+	// Data is the sent or received data.
+	Data []byte
+	// Info is the receiver of sent data, the sender of received data, the
+	// media state name or the error message.
+	Info string
+}
+
+// event is the serialized form of Event.
+type event struct {
+	Time int64     `json:"time"`
+	Type EventType `json:"type"`
+	Data string    `json:"data,omitempty"`
+	Info string    `json:"info,omitempty"`
+}
+
+// MarshalJSON implements json.Marshaler.
+func (e Event) MarshalJSON() ([]byte, error) {
+	return json.Marshal(event{
+		Time: int64(e.Time),
+		Type: e.Type,
+		Data: hex.EncodeToString(e.Data),
+		Info: e.Info,
+	})
+}
+
+// UnmarshalJSON implements json.Unmarshaler.
+func (e *Event) UnmarshalJSON(data []byte) error {
+	var v event
+	if err := json.Unmarshal(data, &v); err != nil {
+		return err
+	}
+	b, err := hex.DecodeString(v.Data)
+	if err != nil {
+		return err
+	}
+	*e = Event{Time: time.Duration(v.Time), Type: v.Type, Data: b, Info: v.Info}
+	return nil
+}
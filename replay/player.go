@@ -0,0 +1,326 @@
+package replay
+
+// --------------------------------------------------------------------------
+//
+//	Gurux Ltd
+//
+// Filename:        $HeadURL$
+//
+// Version:         $Revision$,
+//
+//	$Date$
+//	$Author$
+//
+// # Copyright (c) Gurux Ltd
+//
+// ---------------------------------------------------------------------------
+//
+//	DESCRIPTION
+//
+// This file is a part of Gurux Device Framework.
+//
+// Gurux Device Framework is Open Source software; you can redistribute it
+// and/or modify it under the terms of the GNU General Public License
+// as published by the Free Software Foundation; version 2 of the License.
+// Gurux Device Framework is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.
+// See the GNU General Public License for more details.
+//
+// More information of Gurux products: https://www.gurux.org
+//
+// This code is licensed under the GNU General Public License v2.
+// Full text may be retrieved at http://www.gnu.org/licenses/gpl-2.0.txt
+// ---------------------------------------------------------------------------
+
+import (
+	"bytes"
+	"encoding/binary"
+	"encoding/json"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/Gurux/gxcommon-go"
+)
+
+// MediaType is the media type of Player.
+const MediaType = "Replay"
+
+// Player is an IGXMedia that replays a recording made with Recorder.
+//
+// Data sent with Send must match the next recorded send. If the recorded
+// send failed, Send returns the recorded error. After a matching send, the
+// recorded replies and errors up to the next recorded send are
+// delivered through the received and error events, or to the synchronous
+// receive buffer in synchronous mode. Replies recorded before the first send
+// are delivered when the player is opened. Recorded media state changes are
+// skipped, because the player raises its own state events.
+//
+// By default the replies are delivered immediately, before Send returns. In
+// real-time mode they are delivered asynchronously with the recorded
+// timing.
+type Player struct {
+	gxcommon.GXMediaBase
+	mu       sync.Mutex
+	events   []Event
+	pos      int
+	open     bool
+	realTime bool
+	// generation is incremented when the player is closed so that pending
+	// real-time deliveries are discarded.
+	generation int
+}
+
+// settings is the serialized form of the player settings.
+type settings struct {
+	RealTime  bool   `xml:"RealTime"`
+	ByteOrder string `xml:"ByteOrder"`
+}
+
+// NewPlayer reads a recording from r and returns a Player for it.
+func NewPlayer(r io.Reader) (*Player, error) {
+	ret := &Player{}
+	dec := json.NewDecoder(r)
+	for {
+		var e Event
+		if err := dec.Decode(&e); err == io.EOF {
+			break
+		} else if err != nil {
+			return nil, err
+		}
+		ret.events = append(ret.events, e)
+	}
+	return ret, nil
+}
+
+// RealTime reports whether replies are delivered with the recorded timing.
+func (p *Player) RealTime() bool {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return p.realTime
+}
+
+// SetRealTime sets whether replies are delivered with the recorded timing.
+func (p *Player) SetRealTime(value bool) {
+	p.mu.Lock()
+	p.realTime = value
+	p.mu.Unlock()
+}
+
+// Remaining returns the number of sends, replies and errors that have not
+// been played yet.
+func (p *Player) Remaining() int {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	ret := 0
+	for _, it := range p.events[p.pos:] {
+		if it.Type != EventState {
+			ret++
+		}
+	}
+	return ret
+}
+
+// Send compares data with the next recorded send and plays the replies.
+//
+// It returns ErrMismatch if data does not match the recording and
+// ErrConnectionClosed if the player is not open. If the recorded send
+// failed, the recorded error is returned and no replies are played.
+func (p *Player) Send(data any, receiver string) error {
+	b, err := gxcommon.ToBytes(data, p.GetByteOrder())
+	if err != nil {
+		return err
+	}
+	p.mu.Lock()
+	if !p.open {
+		p.mu.Unlock()
+		return gxcommon.ErrConnectionClosed
+	}
+	for p.pos < len(p.events) && p.events[p.pos].Type == EventState {
+		p.pos++
+	}
+	if p.pos == len(p.events) {
+		p.mu.Unlock()
+		return fmt.Errorf("%w: unexpected %s", ErrMismatch, gxcommon.ToHex(b))
+	}
+	e := p.events[p.pos]
+	if e.Type != EventSend || !bytes.Equal(e.Data, b) {
+		p.mu.Unlock()
+		return fmt.Errorf("%w: expected %s %s, got %s", ErrMismatch, e.Type, gxcommon.ToHex(e.Data), gxcommon.ToHex(b))
+	}
+	p.pos++
+	next := p.pos
+	for next < len(p.events) && p.events[next].Type == EventState {
+		next++
+	}
+	if next < len(p.events) && p.events[next].Type == EventSendError {
+		err = errors.New(p.events[next].Info)
+		p.pos = next + 1
+		p.mu.Unlock()
+		return err
+	}
+	p.mu.Unlock()
+	p.NotifySent(p, b, receiver)
+	p.play(e.Time)
+	return nil
+}
+
+// play delivers the recorded replies and errors up to the next send. start
+// is the recorded time of the previous event.
+func (p *Player) play(start time.Duration) {
+	p.mu.Lock()
+	var events []Event
+	for ; p.pos < len(p.events) && p.events[p.pos].Type != EventSend; p.pos++ {
+		if e := p.events[p.pos]; e.Type != EventState {
+			events = append(events, e)
+		}
+	}
+	realTime := p.realTime
+	generation := p.generation
+	p.mu.Unlock()
+	if !realTime {
+		for _, it := range events {
+			p.deliver(it)
+		}
+		return
+	}
+	now := time.Now()
+	go func() {
+		for _, it := range events {
+			time.Sleep(time.Until(now.Add(it.Time - start)))
+			p.mu.Lock()
+			ok := p.open && p.generation == generation
+			p.mu.Unlock()
+			if !ok {
+				return
+			}
+			p.deliver(it)
+		}
+	}()
+}
+
+// deliver raises the events of a recorded reply or error.
+func (p *Player) deliver(e Event) {
+	switch e.Type {
+	case EventReceive:
+		p.NotifyReceived(p, bytes.Clone(e.Data), e.Info)
+	case EventError:
+		p.NotifyError(p, errors.New(e.Info))
+	}
+}
+
+// Copy copies the player settings, byte order, trace level and
+// end-of-packet marker to target.
+//
+// It returns ErrInvalidArgument if target is not a Player.
+func (p *Player) Copy(target gxcommon.IGXMedia) error {
+	t, ok := target.(*Player)
+	if !ok {
+		return gxcommon.ErrInvalidArgumentError("target")
+	}
+	if err := t.SetSettings(p.GetSettings()); err != nil {
+		return err
+	}
+	if err := t.SetTrace(p.GetTrace()); err != nil {
+		return err
+	}
+	t.SetByteOrder(p.GetByteOrder())
+	t.SetEop(p.GetEop())
+	return nil
+}
+
+// GetName returns the media name.
+func (p *Player) GetName() string {
+	return MediaType
+}
+
+// Open opens the player and plays the replies recorded before the first
+// send.
+func (p *Player) Open() error {
+	p.mu.Lock()
+	if p.open {
+		p.mu.Unlock()
+		return nil
+	}
+	p.mu.Unlock()
+	p.NotifyMediaStateChange(p, gxcommon.MediaStateOpening)
+	p.mu.Lock()
+	p.open = true
+	var start time.Duration
+	if p.pos != 0 {
+		start = p.events[p.pos-1].Time
+	}
+	p.mu.Unlock()
+	p.NotifyMediaStateChange(p, gxcommon.MediaStateOpen)
+	p.play(start)
+	return nil
+}
+
+// IsOpen reports whether the player is open.
+func (p *Player) IsOpen() bool {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return p.open
+}
+
+// Close closes the player. Pending real-time replies are discarded.
+func (p *Player) Close() error {
+	p.mu.Lock()
+	if !p.open {
+		p.mu.Unlock()
+		return nil
+	}
+	p.mu.Unlock()
+	p.NotifyMediaStateChange(p, gxcommon.MediaStateClosing)
+	p.mu.Lock()
+	p.open = false
+	p.generation++
+	p.mu.Unlock()
+	p.NotifyMediaStateChange(p, gxcommon.MediaStateClosed)
+	return nil
+}
+
+// GetMediaType returns MediaType.
+func (p *Player) GetMediaType() string {
+	return MediaType
+}
+
+// GetSettings returns player settings in serialized form.
+func (p *Player) GetSettings() string {
+	ret := "<RealTime>" + strconv.FormatBool(p.RealTime()) + "</RealTime>"
+	if order := p.GetByteOrder(); order != binary.BigEndian {
+		ret += "<ByteOrder>" + order.String() + "</ByteOrder>"
+	}
+	return ret
+}
+
+// SetSettings applies serialized player settings.
+//
+// Settings that are not present in value are left unchanged. ByteOrder is
+// a name accepted by gxcommon.ByteOrderParse.
+func (p *Player) SetSettings(value string) error {
+	s := settings{RealTime: p.RealTime()}
+	if err := xml.Unmarshal([]byte("<Settings>"+value+"</Settings>"), &s); err != nil {
+		return gxcommon.ErrInvalidArgumentError("settings")
+	}
+	order := p.GetByteOrder()
+	if s.ByteOrder != "" {
+		var err error
+		if order, err = gxcommon.ByteOrderParse(s.ByteOrder); err != nil {
+			return err
+		}
+	}
+	p.SetRealTime(s.RealTime)
+	p.SetByteOrder(order)
+	return nil
+}
+
+// Validate validates player settings.
+func (p *Player) Validate() error {
+	return nil
+}
@@ -0,0 +1,118 @@
+package replay
+
+// --------------------------------------------------------------------------
+//
+//	Gurux Ltd
+//
+// Filename:        $HeadURL$
+//
+// Version:         $Revision$,
+//
+//	$Date$
+//	$Author$
+//
+// # Copyright (c) Gurux Ltd
+//
+// ---------------------------------------------------------------------------
+//
+//	DESCRIPTION
+//
+// This file is a part of Gurux Device Framework.
+//
+// Gurux Device Framework is Open Source software; you can redistribute it
+// and/or modify it under the terms of the GNU General Public License
+// as published by the Free Software Foundation; version 2 of the License.
+// Gurux Device Framework is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.
+// See the GNU General Public License for more details.
+//
+// More information of Gurux products: https://www.gurux.org
+//
+// This code is licensed under the GNU General Public License v2.
+// Full text may be retrieved at http://www.gnu.org/licenses/gpl-2.0.txt
+// ---------------------------------------------------------------------------
+
+import (
+	"encoding/json"
+	"io"
+	"sync"
+	"time"
+
+	"github.com/Gurux/gxcommon-go"
+)
+
+// Recorder is an IGXMedia that records the traffic of an inner media.
+//
+// Every send, receive, media state change and error is written to the
+// output as an Event with the time elapsed since the recorder was created.
+// Recording errors are reported by Err.
+type Recorder struct {
+	gxcommon.GXMediaWrapper
+	mu    sync.Mutex
+	enc   *json.Encoder
+	start time.Time
+	err   error
+}
+
+// NewRecorder returns a Recorder that records the traffic of inner to w.
+//
+// The received, error and media state handlers of inner are replaced. Use
+// the handlers of the returned Recorder instead.
+func NewRecorder(inner gxcommon.IGXMedia, w io.Writer) *Recorder {
+	ret := &Recorder{enc: json.NewEncoder(w), start: time.Now()}
+	ret.InitWrapper(ret, inner)
+	return ret
+}
+
+// Err returns the first error that occurred while writing the recording.
+func (r *Recorder) Err() error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.err
+}
+
+// Send records data and sends it with the inner media.
+func (r *Recorder) Send(data any, receiver string) error {
+	b, err := gxcommon.ToBytes(data, r.GetByteOrder())
+	if err != nil {
+		return err
+	}
+	// Data is recorded before it is sent, because the reply can be received
+	// before the inner media returns from Send.
+	r.record(EventSend, b, receiver)
+	if err = r.SendBytes(b, receiver); err != nil {
+		r.record(EventSendError, nil, err.Error())
+		return err
+	}
+	return nil
+}
+
+// NotifyReceived records received data before it is handled.
+func (r *Recorder) NotifyReceived(media gxcommon.IGXMedia, data []byte, sender string) {
+	r.record(EventReceive, data, sender)
+	r.GXMediaWrapper.NotifyReceived(media, data, sender)
+}
+
+// NotifyMediaStateChange records the media state change before the event
+// is raised.
+func (r *Recorder) NotifyMediaStateChange(media gxcommon.IGXMedia, state gxcommon.MediaState) {
+	r.record(EventState, nil, state.String())
+	r.GXMediaWrapper.NotifyMediaStateChange(media, state)
+}
+
+// NotifyError records the error before the event is raised.
+func (r *Recorder) NotifyError(media gxcommon.IGXMedia, err error) {
+	r.record(EventError, nil, err.Error())
+	r.GXMediaWrapper.NotifyError(media, err)
+}
+
+// record writes an event.
+func (r *Recorder) record(t EventType, data []byte, info string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.err != nil {
+		return
+	}
+	r.err = r.enc.Encode(Event{Time: time.Since(r.start), Type: t, Data: data, Info: info})
+}
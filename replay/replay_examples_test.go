@@ -0,0 +1,38 @@
+// Package replay_test holds examples for the replay package.
+package replay_test
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+
+	"github.com/Gurux/gxcommon-go"
+	"github.com/Gurux/gxcommon-go/memory"
+	"github.com/Gurux/gxcommon-go/replay"
+)
+
+// Example records a session with a loopback media and replays it.
+func Example() {
+	var recording bytes.Buffer
+	recorder := replay.NewRecorder(memory.NewLoopback(), &recording)
+	_ = recorder.Open()
+	_ = recorder.Send("Hello", "")
+	_ = recorder.Close()
+
+	player, err := replay.NewPlayer(&recording)
+	if err != nil {
+		fmt.Println(err)
+		return
+	}
+	player.SetOnReceived(func(_ gxcommon.IGXMedia, e gxcommon.ReceiveEventArgs) {
+		fmt.Printf("received %q\n", e.Data())
+	})
+	_ = player.Open()
+	fmt.Println(player.Send("Hello", ""))
+	err = player.Send("Hello", "")
+	fmt.Println(errors.Is(err, replay.ErrMismatch), player.Remaining())
+	// Output:
+	// received "Hello"
+	// <nil>
+	// true 0
+}
@@ -0,0 +1,273 @@
+package replay_test
+
+import (
+	"bytes"
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"io"
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/Gurux/gxcommon-go"
+	"github.com/Gurux/gxcommon-go/mediatest"
+	"github.com/Gurux/gxcommon-go/memory"
+	"github.com/Gurux/gxcommon-go/replay"
+)
+
+func TestRecorderConformance(t *testing.T) {
+	mediatest.RunConformance(t, func(*testing.T) (gxcommon.IGXMedia, gxcommon.IGXMedia) {
+		a, b := memory.NewPair()
+		return replay.NewRecorder(a, io.Discard), replay.NewRecorder(b, io.Discard)
+	})
+}
+
+// newPlayer returns a Player for events.
+func newPlayer(t *testing.T, events ...replay.Event) *replay.Player {
+	t.Helper()
+	var buf bytes.Buffer
+	enc := json.NewEncoder(&buf)
+	for _, it := range events {
+		if err := enc.Encode(it); err != nil {
+			t.Fatal(err)
+		}
+	}
+	ret, err := replay.NewPlayer(&buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return ret
+}
+
+func TestPlayerMismatch(t *testing.T) {
+	p := newPlayer(t,
+		replay.Event{Type: replay.EventSend, Data: []byte("A")},
+		replay.Event{Type: replay.EventReceive, Data: []byte("B")},
+	)
+	if err := p.Send("A", ""); !errors.Is(err, gxcommon.ErrConnectionClosed) {
+		t.Errorf("Send before Open: %v", err)
+	}
+	if err := p.Open(); err != nil {
+		t.Fatal(err)
+	}
+	defer p.Close()
+	c := mediatest.NewCollector(p)
+	if err := p.Send("X", ""); !errors.Is(err, replay.ErrMismatch) {
+		t.Errorf("Send of wrong data: %v", err)
+	}
+	if p.Remaining() != 2 {
+		t.Errorf("Remaining after mismatch: %d", p.Remaining())
+	}
+	if err := p.Send("A", ""); err != nil {
+		t.Fatal(err)
+	}
+	if received := c.ReceivedStrings(); !slices.Equal(received, []string{"B"}) {
+		t.Errorf("received %q", received)
+	}
+	if err := p.Send("A", ""); !errors.Is(err, replay.ErrMismatch) {
+		t.Errorf("Send after the recording: %v", err)
+	}
+}
+
+func TestPlayerOpen(t *testing.T) {
+	p := newPlayer(t,
+		replay.Event{Type: replay.EventState, Info: "Open"},
+		replay.Event{Type: replay.EventReceive, Data: []byte("Welcome")},
+		replay.Event{Type: replay.EventSend, Data: []byte("A")},
+	)
+	c := mediatest.NewCollector(p)
+	if p.Remaining() != 2 {
+		t.Errorf("Remaining: got %d, want 2", p.Remaining())
+	}
+	if err := p.Open(); err != nil {
+		t.Fatal(err)
+	}
+	defer p.Close()
+	if received := c.ReceivedStrings(); !slices.Equal(received, []string{"Welcome"}) {
+		t.Errorf("received %q", received)
+	}
+	if p.Remaining() != 1 {
+		t.Errorf("Remaining after Open: got %d, want 1", p.Remaining())
+	}
+	if err := p.Send("A", ""); err != nil || p.Remaining() != 0 {
+		t.Errorf("Send: %v, Remaining %d", err, p.Remaining())
+	}
+}
+
+func TestPlayerError(t *testing.T) {
+	p := newPlayer(t,
+		replay.Event{Type: replay.EventSend, Data: []byte("A")},
+		replay.Event{Type: replay.EventError, Info: "line broken"},
+		replay.Event{Type: replay.EventReceive, Data: []byte("B")},
+	)
+	c := mediatest.NewCollector(p)
+	if err := p.Open(); err != nil {
+		t.Fatal(err)
+	}
+	defer p.Close()
+	if err := p.Send("A", ""); err != nil {
+		t.Fatal(err)
+	}
+	received, errs := c.ReceivedStrings(), c.Errors()
+	if len(errs) != 1 || errs[0].Error() != "line broken" || !slices.Equal(received, []string{"B"}) {
+		t.Errorf("got errors %v and received %q", errs, received)
+	}
+}
+
+func TestPlayerSendError(t *testing.T) {
+	p := newPlayer(t,
+		replay.Event{Type: replay.EventSend, Data: []byte("A")},
+		replay.Event{Type: replay.EventState, Info: "Closed"},
+		replay.Event{Type: replay.EventSendError, Info: "port closed"},
+		replay.Event{Type: replay.EventSend, Data: []byte("B")},
+		replay.Event{Type: replay.EventReceive, Data: []byte("C")},
+	)
+	c := mediatest.NewCollector(p)
+	if err := p.Open(); err != nil {
+		t.Fatal(err)
+	}
+	defer p.Close()
+	if err := p.Send("A", ""); err == nil || err.Error() != "port closed" {
+		t.Errorf("Send: %v, want the recorded error", err)
+	}
+	if p.GetBytesSent() != 0 || p.Remaining() != 2 {
+		t.Errorf("failed Send: %d bytes sent, Remaining %d", p.GetBytesSent(), p.Remaining())
+	}
+	if err := p.Send("B", ""); err != nil {
+		t.Fatal(err)
+	}
+	if received, errs := c.ReceivedStrings(), c.Errors(); !slices.Equal(received, []string{"C"}) || len(errs) != 0 {
+		t.Errorf("got errors %v and received %q", errs, received)
+	}
+}
+
+func TestPlayerRealTime(t *testing.T) {
+	p := newPlayer(t,
+		replay.Event{Type: replay.EventSend, Data: []byte("A")},
+		replay.Event{Time: 50 * time.Millisecond, Type: replay.EventReceive, Data: []byte("B")},
+		replay.Event{Time: 60 * time.Millisecond, Type: replay.EventSend, Data: []byte("C")},
+		replay.Event{Time: 200 * time.Millisecond, Type: replay.EventReceive, Data: []byte("D")},
+	)
+	p.SetRealTime(true)
+	c := mediatest.NewCollector(p)
+	if err := p.Open(); err != nil {
+		t.Fatal(err)
+	}
+	start := time.Now()
+	if err := p.Send("A", ""); err != nil {
+		t.Fatal(err)
+	}
+	if received := c.ReceivedStrings(); len(received) != 0 {
+		t.Errorf("received %q before the recorded time", received)
+	}
+	if !c.Wait(1, 5*time.Second) {
+		t.Fatal("reply was not delivered")
+	}
+	if elapsed := c.Times()[0].Sub(start); elapsed < 50*time.Millisecond {
+		t.Errorf("reply was delivered after %v", elapsed)
+	}
+	if err := p.Send("C", ""); err != nil {
+		t.Fatal(err)
+	}
+	// Reopening must not deliver the reply of the previous session.
+	_ = p.Close()
+	if err := p.Open(); err != nil {
+		t.Fatal(err)
+	}
+	defer p.Close()
+	time.Sleep(300 * time.Millisecond)
+	if received := c.ReceivedStrings(); !slices.Equal(received, []string{"B"}) {
+		t.Errorf("received %q after Close", received)
+	}
+}
+
+func TestPlayerSettings(t *testing.T) {
+	p := newPlayer(t, replay.Event{Type: replay.EventSend, Data: []byte{0x02, 0x01}})
+	if got := p.GetSettings(); got != "<RealTime>false</RealTime>" {
+		t.Errorf("GetSettings: got %s", got)
+	}
+	if err := p.SetSettings("<ByteOrder>DCBA</ByteOrder>"); err != nil {
+		t.Fatal(err)
+	}
+	if err := p.SetSettings("<RealTime>true</RealTime>"); err != nil {
+		t.Fatal(err)
+	}
+	if got := p.GetSettings(); got != "<RealTime>true</RealTime><ByteOrder>LittleEndian</ByteOrder>" {
+		t.Errorf("GetSettings: got %s", got)
+	}
+	if err := p.SetSettings("<ByteOrder>Middle</ByteOrder>"); !errors.Is(err, gxcommon.ErrUnknownEnum) {
+		t.Errorf("SetSettings: got %v", err)
+	}
+	if p.GetByteOrder() != binary.LittleEndian {
+		t.Errorf("invalid settings changed the byte order to %v", p.GetByteOrder())
+	}
+	c := newPlayer(t)
+	if err := p.Copy(c); err != nil || c.GetSettings() != p.GetSettings() || c.GetByteOrder() != binary.LittleEndian {
+		t.Errorf("Copy: got %s, %v, %v", c.GetSettings(), c.GetByteOrder(), err)
+	}
+	p.SetRealTime(false)
+	if err := p.Open(); err != nil {
+		t.Fatal(err)
+	}
+	defer p.Close()
+	if err := p.Send(uint16(0x0102), ""); err != nil {
+		t.Errorf("Send in little-endian: %v", err)
+	}
+}
+
+func TestRecorderPlayer(t *testing.T) {
+	a, device := memory.NewPair()
+	device.SetOnReceived(func(_ gxcommon.IGXMedia, e gxcommon.ReceiveEventArgs) {
+		_ = device.Send(append([]byte("re:"), e.Data()...), "")
+	})
+	if err := device.Open(); err != nil {
+		t.Fatal(err)
+	}
+	var recording bytes.Buffer
+	r := replay.NewRecorder(a, &recording)
+	// The inner media is not open yet, so the send fails.
+	sendErr := r.Send("closed", "")
+	if sendErr == nil {
+		t.Fatal("Send before Open succeeded")
+	}
+	if err := r.Open(); err != nil {
+		t.Fatal(err)
+	}
+	for _, it := range []string{"one", "two"} {
+		if err := r.Send(it, ""); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := r.Close(); err != nil || r.Err() != nil {
+		t.Fatal(err, r.Err())
+	}
+
+	p, err := replay.NewPlayer(&recording)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err = p.Open(); err != nil {
+		t.Fatal(err)
+	}
+	defer p.Close()
+	if err = p.Send("closed", ""); err == nil || err.Error() != sendErr.Error() {
+		t.Errorf("Send of the failed send: %v, want %v", err, sendErr)
+	}
+	release := p.GetSynchronous()
+	defer release()
+	for _, it := range []string{"one", "two"} {
+		if err = p.Send(it, ""); err != nil {
+			t.Fatal(err)
+		}
+		args := gxcommon.NewReceiveParameters[string]()
+		args.Count = len(it) + 3
+		args.WaitTime = 1000
+		if ok, err := p.Receive(args); !ok || err != nil || args.Reply != "re:"+it {
+			t.Errorf("Receive: %v, %v, %v", ok, err, args.Reply)
+		}
+	}
+	if p.Remaining() != 0 {
+		t.Errorf("Remaining: %d", p.Remaining())
+	}
+}
@@ -0,0 +1,193 @@
+package throttle
+
+// --------------------------------------------------------------------------
+//
+//	Gurux Ltd
+//
+// Filename:        $HeadURL$
+//
+// Version:         $Revision$,
+//
+//	$Date$
+//	$Author$
+//
+// # Copyright (c) Gurux Ltd
+//
+// ---------------------------------------------------------------------------
+//
+//	DESCRIPTION
+//
+// This file is a part of Gurux Device Framework.
+//
+// Gurux Device Framework is Open Source software; you can redistribute it
+// and/or modify it under the terms of the GNU General Public License
+// as published by the Free Software Foundation; version 2 of the License.
+// Gurux Device Framework is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.
+// See the GNU General Public License for more details.
+//
+// More information of Gurux products: https://www.gurux.org
+//
+// This code is licensed under the GNU General Public License v2.
+// Full text may be retrieved at http://www.gnu.org/licenses/gpl-2.0.txt
+// ---------------------------------------------------------------------------
+
+import (
+	"bytes"
+	"sync"
+	"time"
+
+	"github.com/Gurux/gxcommon-go"
+)
+
+// Media is an IGXMedia that throttles the data of an inner media to the
+// speed of a serial line.
+//
+// Send waits until the previously sent data has been transmitted and then
+// for the transmit time of data before it sends data with the inner media.
+// Received data is delivered in order after its transmit time, counted from
+// when it was received or from when the previously received data was
+// delivered. Pending received data is discarded and waiting sends return
+// ErrConnectionClosed when the media is closed.
+type Media struct {
+	gxcommon.GXMediaWrapper
+	mu       sync.Mutex
+	settings gxcommon.SerialSettings
+	// sendMu serializes sends.
+	sendMu sync.Mutex
+	// sendFree is the time when the sent data has been transmitted.
+	sendFree time.Time
+	// receiveFree is the time when the received data has been delivered.
+	receiveFree time.Time
+	// received is closed when the last received data has been delivered.
+	received chan struct{}
+	// generation is incremented when the media is closed so that pending
+	// received data is discarded.
+	generation int
+	// closed is closed when the media is closed to stop waiting sends.
+	closed chan struct{}
+}
+
+// New returns a Media that throttles inner to the speed given by settings.
+//
+// The received, error and media state handlers of inner are replaced. Use
+// the handlers of the returned Media instead.
+func New(inner gxcommon.IGXMedia, settings gxcommon.SerialSettings) *Media {
+	ret := &Media{settings: settings}
+	ret.InitWrapper(ret, inner)
+	return ret
+}
+
+// SerialSettings returns the emulated serial settings.
+func (m *Media) SerialSettings() gxcommon.SerialSettings {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.settings
+}
+
+// SetSerialSettings sets the emulated serial settings. The new settings are
+// used for data that is sent or received after the call.
+func (m *Media) SetSerialSettings(value gxcommon.SerialSettings) {
+	m.mu.Lock()
+	m.settings = value
+	m.mu.Unlock()
+}
+
+// Send waits for the transmit time of data and sends it with the inner
+// media.
+//
+// If the media is closed while Send is waiting, data is discarded and Send
+// returns ErrConnectionClosed.
+func (m *Media) Send(data any, receiver string) error {
+	b, err := gxcommon.ToBytes(data, m.GetByteOrder())
+	if err != nil {
+		return err
+	}
+	closed := m.closedChan()
+	if !m.IsOpen() {
+		return gxcommon.ErrConnectionClosed
+	}
+	m.sendMu.Lock()
+	defer m.sendMu.Unlock()
+	start := time.Now()
+	if start.Before(m.sendFree) {
+		start = m.sendFree
+	}
+	m.sendFree = start.Add(m.SerialSettings().TransmitTime(len(b)))
+	t := time.NewTimer(time.Until(m.sendFree))
+	defer t.Stop()
+	select {
+	case <-t.C:
+	case <-closed:
+		m.sendFree = time.Time{}
+		return gxcommon.ErrConnectionClosed
+	}
+	return m.SendBytes(b, receiver)
+}
+
+// closedChan returns the channel that is closed when the media is closed.
+func (m *Media) closedChan() <-chan struct{} {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if m.closed == nil {
+		m.closed = make(chan struct{})
+	}
+	return m.closed
+}
+
+// NotifyReceived delivers the data received from the inner media after its
+// transmit time.
+func (m *Media) NotifyReceived(media gxcommon.IGXMedia, data []byte, sender string) {
+	data = bytes.Clone(data)
+	m.mu.Lock()
+	start := time.Now()
+	if start.Before(m.receiveFree) {
+		start = m.receiveFree
+	}
+	at := start.Add(m.settings.TransmitTime(len(data)))
+	m.receiveFree = at
+	previous := m.received
+	done := make(chan struct{})
+	m.received = done
+	generation := m.generation
+	m.mu.Unlock()
+	go func() {
+		defer close(done)
+		if previous != nil {
+			<-previous
+		}
+		time.Sleep(time.Until(at))
+		m.mu.Lock()
+		ok := m.generation == generation
+		m.mu.Unlock()
+		if ok {
+			m.GXMediaWrapper.NotifyReceived(media, data, sender)
+		}
+	}()
+}
+
+// NotifyMediaStateChange discards pending received data and stops waiting
+// sends when the media is closed.
+func (m *Media) NotifyMediaStateChange(media gxcommon.IGXMedia, state gxcommon.MediaState) {
+	if state == gxcommon.MediaStateClosed {
+		m.mu.Lock()
+		m.generation++
+		m.receiveFree = time.Time{}
+		if m.closed != nil {
+			close(m.closed)
+			m.closed = nil
+		}
+		m.mu.Unlock()
+	}
+	m.GXMediaWrapper.NotifyMediaStateChange(media, state)
+}
+
+// Validate validates the serial settings and the settings of the inner
+// media.
+func (m *Media) Validate() error {
+	if err := m.SerialSettings().Validate(); err != nil {
+		return err
+	}
+	return m.GXMediaWrapper.Validate()
+}
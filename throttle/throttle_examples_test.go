@@ -0,0 +1,28 @@
+// Package throttle_test holds examples for the throttle package.
+package throttle_test
+
+import (
+	"fmt"
+
+	"github.com/Gurux/gxcommon-go"
+	"github.com/Gurux/gxcommon-go/memory"
+	"github.com/Gurux/gxcommon-go/throttle"
+)
+
+// ExampleNew emulates a 300 baud optical head over a loopback media. The
+// 10 byte message takes 333 ms to send and another 333 ms to receive.
+func ExampleNew() {
+	s, _ := gxcommon.ParseSerialSettings("300,7E1")
+	m := throttle.New(memory.NewLoopback(), s)
+	_ = m.Open()
+	defer m.Close()
+	r := m.GetSynchronous()
+	defer r()
+	_ = m.Send("0123456789", "")
+	p := gxcommon.NewReceiveParameters[string]()
+	p.Count = 10
+	_, _ = m.Receive(p)
+	fmt.Println(p.Reply)
+	// Output:
+	// 0123456789
+}
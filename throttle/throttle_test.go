@@ -0,0 +1,181 @@
+package throttle_test
+
+import (
+	"errors"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/Gurux/gxcommon-go"
+	"github.com/Gurux/gxcommon-go/mediatest"
+	"github.com/Gurux/gxcommon-go/memory"
+	"github.com/Gurux/gxcommon-go/throttle"
+)
+
+func TestConformance(t *testing.T) {
+	mediatest.RunConformance(t, func(*testing.T) (gxcommon.IGXMedia, gxcommon.IGXMedia) {
+		a, b := memory.NewPair()
+		s := gxcommon.DefaultSerialSettings()
+		s.BaudRate = gxcommon.BaudRate115200
+		return throttle.New(a, s), throttle.New(b, s)
+	})
+}
+
+// settings returns 8N1 serial settings with the given baud rate.
+func settings(baudRate gxcommon.BaudRate) gxcommon.SerialSettings {
+	ret := gxcommon.DefaultSerialSettings()
+	ret.BaudRate = baudRate
+	return ret
+}
+
+func TestSendTime(t *testing.T) {
+	s := settings(gxcommon.BaudRate1200)
+	a, b := memory.NewPair()
+	m := throttle.New(a, s)
+	if err := b.Open(); err != nil {
+		t.Fatal(err)
+	}
+	defer b.Close()
+	if err := m.Open(); err != nil {
+		t.Fatal(err)
+	}
+	defer m.Close()
+	start := time.Now()
+	if err := m.Send("0123456789", ""); err != nil {
+		t.Fatal(err)
+	}
+	if elapsed, want := time.Since(start), s.TransmitTime(10); elapsed < want {
+		t.Errorf("Send took %v, want at least %v", elapsed, want)
+	}
+	if err := m.Send("0123456789", ""); err != nil {
+		t.Fatal(err)
+	}
+	if elapsed, want := time.Since(start), s.TransmitTime(20); elapsed < want {
+		t.Errorf("two sends took %v, want at least %v", elapsed, want)
+	}
+}
+
+func TestSendClose(t *testing.T) {
+	s := settings(gxcommon.BaudRate300)
+	a, b := memory.NewPair()
+	m := throttle.New(a, s)
+	c := mediatest.NewCollector(b)
+	if err := b.Open(); err != nil {
+		t.Fatal(err)
+	}
+	defer b.Close()
+	if err := m.Open(); err != nil {
+		t.Fatal(err)
+	}
+	data := strings.Repeat("A", 300)
+	errs := make(chan error, 2)
+	for range 2 {
+		go func() {
+			errs <- m.Send(data, "")
+		}()
+	}
+	time.Sleep(20 * time.Millisecond)
+	start := time.Now()
+	if err := m.Close(); err != nil {
+		t.Fatal(err)
+	}
+	for range 2 {
+		select {
+		case err := <-errs:
+			if !errors.Is(err, gxcommon.ErrConnectionClosed) {
+				t.Errorf("Send: %v", err)
+			}
+		case <-time.After(5 * time.Second):
+			t.Fatal("Close did not stop a waiting Send")
+		}
+	}
+	if elapsed := time.Since(start); elapsed > s.TransmitTime(len(data))/2 {
+		t.Errorf("Send returned %v after Close", elapsed)
+	}
+	if received := c.ReceivedStrings(); len(received) != 0 {
+		t.Errorf("received %q", received)
+	}
+	// The discarded data does not delay sends after the media is opened
+	// again.
+	if err := m.Open(); err != nil {
+		t.Fatal(err)
+	}
+	defer m.Close()
+	start = time.Now()
+	if err := m.Send("A", ""); err != nil {
+		t.Fatal(err)
+	}
+	if elapsed := time.Since(start); elapsed > s.TransmitTime(len(data))/2 {
+		t.Errorf("Send after Open took %v", elapsed)
+	}
+}
+
+func TestReceive(t *testing.T) {
+	s := settings(gxcommon.BaudRate1200)
+	a, b := memory.NewPair()
+	m := throttle.New(a, s)
+	c := mediatest.NewCollector(m)
+	if err := m.Open(); err != nil {
+		t.Fatal(err)
+	}
+	defer m.Close()
+	if err := b.Open(); err != nil {
+		t.Fatal(err)
+	}
+	defer b.Close()
+	start := time.Now()
+	for _, it := range []string{"A", "BB", "CCC", "DDDD"} {
+		if err := b.Send(it, ""); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if received := c.ReceivedStrings(); len(received) != 0 {
+		t.Errorf("received %q before the transmit time", received)
+	}
+	if !c.Wait(4, 5*time.Second) {
+		t.Fatalf("received %q", c.ReceivedStrings())
+	}
+	received, times := c.ReceivedStrings(), c.Times()
+	if !slices.Equal(received, []string{"A", "BB", "CCC", "DDDD"}) {
+		t.Errorf("received %q", received)
+	}
+	// The delays accumulate, so the nth data is delivered after the
+	// transmit time of all data before it.
+	size := 0
+	for i, it := range received {
+		size += len(it)
+		if elapsed, want := times[i].Sub(start), s.TransmitTime(size); elapsed < want {
+			t.Errorf("%q was delivered after %v, want at least %v", it, elapsed, want)
+		}
+	}
+}
+
+func TestReceiveClose(t *testing.T) {
+	s := settings(gxcommon.BaudRate1200)
+	a, b := memory.NewPair()
+	m := throttle.New(a, s)
+	c := mediatest.NewCollector(m)
+	if err := m.Open(); err != nil {
+		t.Fatal(err)
+	}
+	if err := b.Open(); err != nil {
+		t.Fatal(err)
+	}
+	defer b.Close()
+	if err := b.Send("0123456789", ""); err != nil {
+		t.Fatal(err)
+	}
+	if err := m.Close(); err != nil {
+		t.Fatal(err)
+	}
+	// Pending data must not be delivered after the media is opened again.
+	if err := m.Open(); err != nil {
+		t.Fatal(err)
+	}
+	defer m.Close()
+	time.Sleep(3 * s.TransmitTime(10))
+	if received := c.ReceivedStrings(); len(received) != 0 {
+		t.Errorf("received %q after Close", received)
+	}
+}